@@ -0,0 +1,118 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var addressBookAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add or replace an address book destination.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry := &utils.AddressBookEntry{
+			Name:           utils.GetFlagStringValue(cmd, utils.NameFlag),
+			Label:          utils.GetFlagStringValue(cmd, utils.LabelFlag),
+			Type:           utils.AddressBookTypeCrypto,
+			AssetId:        utils.GetFlagStringValue(cmd, utils.AssetIdFlag),
+			NetworkArnId:   utils.GetFlagStringValue(cmd, utils.NetworkArnIdFlag),
+			Address:        utils.GetFlagStringValue(cmd, utils.AddressFlag),
+			CounterpartyId: utils.GetFlagStringValue(cmd, utils.CounterpartyIdFlag),
+			CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+		}
+		if entry.CounterpartyId != "" {
+			entry.Type = utils.AddressBookTypeCounterparty
+		}
+
+		if err := entry.Validate(); err != nil {
+			return fmt.Errorf("invalid destination: %w", err)
+		}
+
+		err := utils.UpdateAddressBook(func(book *utils.AddressBook) error {
+			book.Entries[entry.Name] = entry
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot save address book: %w", err)
+		}
+
+		return utils.PrintJsonResponse(cmd, entry)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: addressBookAddCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.NameFlag,
+					Shorthand:    "n",
+					Usage:        "Unique name of the destination, e.g. treasury-cold (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.LabelFlag,
+					Shorthand:    "l",
+					Usage:        "Free-form description of the destination",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AssetIdFlag,
+					Shorthand:    "i",
+					Usage:        "Asset ID of the destination (Required for crypto addresses)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.NetworkArnIdFlag,
+					Shorthand:    "a",
+					Usage:        "Network Arn Id of the address (Required for crypto addresses)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AddressFlag,
+					Shorthand:    "d",
+					Usage:        "Crypto address of the destination",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.CounterpartyIdFlag,
+					Shorthand:    "c",
+					Usage:        "Counterparty ID of the destination, instead of a crypto address",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(addressBookCmd, cmdConfigs)
+}
@@ -0,0 +1,63 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var addressBookEnforceCmd = &cobra.Command{
+	Use:   "enforce-allowlist",
+	Short: "Enable or disable refusing withdrawals to unlisted destinations.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enabled, err := cmd.Flags().GetBool(utils.EnabledFlag)
+		if err != nil {
+			return fmt.Errorf("cannot read enabled flag: %w", err)
+		}
+
+		err = utils.UpdateAddressBook(func(book *utils.AddressBook) error {
+			book.EnforceAllowlist = enabled
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot save address book: %w", err)
+		}
+
+		fmt.Printf("allowlist enforcement enabled: %t\n", enabled)
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: addressBookEnforceCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.EnabledFlag,
+					Shorthand:    "e",
+					Usage:        "Pass true to refuse withdrawals to destinations not in the address book",
+					DefaultValue: false,
+					Required:     true,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(addressBookCmd, cmdConfigs)
+}
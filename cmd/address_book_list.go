@@ -0,0 +1,62 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var addressBookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List address book destinations.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		book, err := utils.LoadAddressBook()
+		if err != nil {
+			return fmt.Errorf("cannot load address book: %w", err)
+		}
+
+		response := struct {
+			EnforceAllowlist bool                      `json:"enforceAllowlist"`
+			Entries          []*utils.AddressBookEntry `json:"entries"`
+		}{
+			EnforceAllowlist: book.EnforceAllowlist,
+			Entries:          book.SortedEntries(),
+		}
+
+		return utils.PrintJsonResponse(cmd, response)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: addressBookListCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(addressBookCmd, cmdConfigs)
+}
@@ -0,0 +1,63 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var addressBookRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove an address book destination.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := utils.GetFlagStringValue(cmd, utils.NameFlag)
+
+		err := utils.UpdateAddressBook(func(book *utils.AddressBook) error {
+			if _, ok := book.Entries[name]; !ok {
+				return fmt.Errorf("address book entry %s not found", name)
+			}
+			delete(book.Entries, name)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot remove destination: %w", err)
+		}
+
+		fmt.Printf("removed %s\n", name)
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: addressBookRemoveCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.NameFlag,
+					Shorthand:    "n",
+					Usage:        "Name of the destination to remove (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(addressBookCmd, cmdConfigs)
+}
@@ -0,0 +1,85 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var alertsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate an alert rules file and optionally send a test alert to every channel.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rules, err := utils.LoadAlertRules(utils.GetFlagStringValue(cmd, utils.RulesFlag))
+		if err != nil {
+			return err
+		}
+
+		dispatcher := utils.NewAlertDispatcher(rules, os.Stdout)
+		fmt.Printf("%d rules, channels %v\n", len(rules.Rules), dispatcher.Channels())
+
+		if !*utils.GetFlagBoolValue(cmd, utils.SendFlag) {
+			return nil
+		}
+
+		errs := dispatcher.Dispatch(&utils.Alert{
+			Rule:    "test",
+			Type:    "test",
+			Subject: "test",
+			Message: "Test alert from intxctl",
+			Time:    time.Now(),
+		})
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		if len(errs) > 0 {
+			cmd.SilenceUsage = true
+			return errors.New("test alert could not be delivered to every channel")
+		}
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: alertsCheckCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.RulesFlag,
+					Shorthand:    "r",
+					Usage:        "YAML file with channels and rules (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.SendFlag,
+					Usage:        "Send a test alert to every channel",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(alertsCmd, cmdConfigs)
+}
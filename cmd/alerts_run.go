@@ -0,0 +1,91 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var alertsRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Evaluate alert rules on their schedule and dispatch alerts until interrupted.",
+	Long: "Evaluate alert rules on their schedule and dispatch alerts until interrupted.\n\n" +
+		"Price rules alert when the price crosses a level; funding and margin rules when the value is above " +
+		"or below a level. Each alerts once until its condition clears. Position, fill and transfer rules alert " +
+		"on changes after startup. Repeated alerts for the same rule and subject within the cooldown are suppressed.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rules, err := utils.LoadAlertRules(utils.GetFlagStringValue(cmd, utils.RulesFlag))
+		if err != nil {
+			return err
+		}
+
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, rules.NeedsPortfolio())
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		engine := utils.NewAlertEngine(rules, client, portfolioId, time.Now())
+		dispatcher := utils.NewAlertDispatcher(rules, os.Stdout)
+		fmt.Fprintf(os.Stderr, "Evaluating %d rules, notifying %v\n", len(rules.Rules), dispatcher.Channels())
+
+		for {
+			evaluation := engine.Evaluate(time.Now())
+			for _, err := range evaluation.Errors {
+				fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+			}
+			for _, alert := range evaluation.Suppressed {
+				fmt.Fprintf(os.Stderr, "Suppressed during cooldown: %s\n", utils.FormatAlert(alert))
+			}
+			for _, alert := range evaluation.Alerts {
+				for _, err := range dispatcher.Dispatch(alert) {
+					fmt.Fprintf(os.Stderr, "WARNING: cannot dispatch alert %s: %v\n", alert.Rule, err)
+				}
+			}
+			time.Sleep(time.Until(engine.NextRun()))
+		}
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: alertsRunCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.RulesFlag,
+					Shorthand:    "r",
+					Usage:        "YAML file with channels and rules (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID for rules that do not name one. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(alertsCmd, cmdConfigs)
+}
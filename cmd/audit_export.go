@@ -0,0 +1,119 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export audit log records as JSON lines or CSV.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := readFilteredAuditRecords(cmd)
+		if err != nil {
+			return err
+		}
+
+		out, err := utils.OpenOutput(utils.GetFlagStringValue(cmd, utils.OutputFlag))
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		switch format := utils.GetFlagStringValue(cmd, utils.OutputFormatFlag); format {
+		case "jsonl":
+			encoder := json.NewEncoder(out)
+			for _, record := range records {
+				if err := encoder.Encode(record); err != nil {
+					return fmt.Errorf("cannot write record: %w", err)
+				}
+			}
+			return nil
+		case "csv":
+			w := csv.NewWriter(out)
+			if err := w.Write([]string{
+				"sequence", "timestamp", "user", "host", "profile", "environment", "base_url",
+				"command", "outcome", "error", "request", "response", "prev_hash", "hash",
+			}); err != nil {
+				return fmt.Errorf("cannot write header: %w", err)
+			}
+			for _, r := range records {
+				if err := w.Write([]string{
+					strconv.FormatInt(r.Sequence, 10), r.Timestamp, r.User, r.Host, r.Profile, r.Environment, r.BaseUrl,
+					r.Command, r.Outcome, r.Error, string(r.Request), string(r.Response), r.PrevHash, r.Hash,
+				}); err != nil {
+					return fmt.Errorf("cannot write record: %w", err)
+				}
+			}
+			w.Flush()
+			return w.Error()
+		default:
+			return fmt.Errorf("unsupported format %s: must be jsonl or csv", format)
+		}
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: auditExportCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "f",
+					Usage:        "Export format: jsonl or csv",
+					DefaultValue: "jsonl",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFlag,
+					Shorthand:    "o",
+					Usage:        "Output file. Writes to stdout if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.CommandFlag,
+					Shorthand:    "c",
+					Usage:        "Filter records by command, e.g. create-order",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TimeFromFlag,
+					Usage:        "Filter records from this time (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TimeToFlag,
+					Usage:        "Filter records before this time (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(auditCmd, cmdConfigs)
+}
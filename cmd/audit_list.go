@@ -0,0 +1,123 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit log records.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := readFilteredAuditRecords(cmd)
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetInt(utils.ResultLimitFlag)
+		if limit > 0 && len(records) > limit {
+			records = records[len(records)-limit:]
+		}
+
+		return utils.PrintJsonResponse(cmd, records)
+	},
+}
+
+func readFilteredAuditRecords(cmd *cobra.Command) ([]*utils.AuditRecord, error) {
+	path, err := utils.GetAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := utils.ReadAuditRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := utils.GetFlagTimeValue(cmd, utils.TimeFromFlag)
+	if err != nil {
+		return nil, err
+	}
+	to, err := utils.GetFlagTimeValue(cmd, utils.TimeToFlag)
+	if err != nil {
+		return nil, err
+	}
+	command := utils.GetFlagStringValue(cmd, utils.CommandFlag)
+
+	filtered := []*utils.AuditRecord{}
+	for _, record := range records {
+		if command != "" && record.Command != command && record.Command != rootCmd.Name()+" "+command {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339Nano, record.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("record %d has invalid timestamp: %w", record.Sequence, err)
+		}
+		if utils.InTimeRange(timestamp, from, to) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: auditListCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.CommandFlag,
+					Shorthand:    "c",
+					Usage:        "Filter records by command, e.g. create-order",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TimeFromFlag,
+					Usage:        "Filter records from this time (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TimeToFlag,
+					Usage:        "Filter records before this time (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ResultLimitFlag,
+					Usage:        "Only show the most recent records",
+					DefaultValue: 0,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(auditCmd, cmdConfigs)
+}
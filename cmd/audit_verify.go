@@ -0,0 +1,50 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the hash chain of the audit log.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := utils.GetAuditLogPath()
+		if err != nil {
+			return err
+		}
+
+		records, err := utils.ReadAuditRecords(path)
+		if err != nil {
+			return err
+		}
+
+		if err := utils.VerifyAuditRecords(records); err != nil {
+			return fmt.Errorf("audit log %s failed verification: %w", path, err)
+		}
+
+		fmt.Printf("audit log %s verified: %d records\n", path, len(records))
+		return nil
+	},
+}
+
+func init() {
+	utils.RegisterCommandConfigs(auditCmd, []utils.CommandConfig{{Command: auditVerifyCmd}})
+}
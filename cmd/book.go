@@ -0,0 +1,201 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type bookReport struct {
+	Instrument string                  `json:"instrument"`
+	Time       string                  `json:"time"`
+	Spread     *utils.BookSpread       `json:"spread"`
+	Bids       []*utils.DepthLevel     `json:"bids"`
+	Asks       []*utils.DepthLevel     `json:"asks"`
+	Impact     []*utils.ImpactEstimate `json:"impact,omitempty"`
+}
+
+var bookCmd = &cobra.Command{
+	Use:   "book",
+	Short: "Show order book depth, spread and market impact for an instrument.",
+	Long: "Show order book depth, spread and market impact for an instrument.\n\n" +
+		"The book is a snapshot of the LEVEL2 channel of the market data feed. Levels can be grouped into price " +
+		"buckets with --group; bids round down and asks round up. --impact estimates the average price, worst price " +
+		"and slippage from the mid of market orders of the given sizes on each side.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := utils.InitClientAndPortfolioId(cmd, false)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		environment, err := utils.ResolveEnvironment(cmd)
+		if err != nil {
+			return err
+		}
+		if environment.MarketDataUrl == "" {
+			return fmt.Errorf("no market data feed for the %s environment: set %s or marketDataUrl in the profile", environment.Name, utils.MarketDataEnvVar)
+		}
+
+		group, err := utils.ParseAmount(utils.GetFlagStringValue(cmd, utils.GroupFlag))
+		if err != nil || group < 0 {
+			return fmt.Errorf("invalid --%s: use a price increment such as 10", utils.GroupFlag)
+		}
+		depth, _ := cmd.Flags().GetInt(utils.DepthFlag)
+
+		var sizes []float64
+		if value := utils.GetFlagStringValue(cmd, utils.ImpactFlag); value != "" {
+			for _, s := range strings.Split(value, ",") {
+				size, err := utils.ParseAmount(s)
+				if err != nil || size <= 0 {
+					return fmt.Errorf("invalid --%s %s: use comma separated positive sizes", utils.ImpactFlag, value)
+				}
+				sizes = append(sizes, size)
+			}
+		}
+
+		ctx, cancel := utils.GetContextWithTimeout()
+		defer cancel()
+
+		book, err := utils.FetchOrderBook(ctx, client, environment.MarketDataUrl, utils.GetFlagStringValue(cmd, utils.InstrumentIdFlag))
+		if err != nil {
+			return err
+		}
+
+		report := &bookReport{
+			Instrument: book.Instrument,
+			Time:       book.Time,
+			Spread:     book.Spread(),
+			Bids:       limitDepth(utils.GroupBookLevels(book.Bids, group, true), depth),
+			Asks:       limitDepth(utils.GroupBookLevels(book.Asks, group, false), depth),
+		}
+		for _, size := range sizes {
+			report.Impact = append(report.Impact, book.EstimateImpact(utils.SideBuy, size), book.EstimateImpact(utils.SideSell, size))
+		}
+
+		spreadReport := &utils.Report{Headers: []string{"INSTRUMENT", "TIME", "BEST_BID", "BEST_ASK", "MID", "SPREAD", "SPREAD_BPS"}}
+		s := report.Spread
+		spreadReport.AddRow(
+			report.Instrument,
+			report.Time,
+			utils.FormatAmount(s.BestBid),
+			utils.FormatAmount(s.BestAsk),
+			utils.FormatAmount(s.Mid),
+			utils.FormatRounded(s.Spread, 8),
+			utils.FormatRounded(s.SpreadBps, 2),
+		)
+
+		depthReport := &utils.Report{Headers: []string{"SIDE", "PRICE", "SIZE", "CUM_SIZE", "CUM_NOTIONAL"}}
+		for i := len(report.Asks) - 1; i >= 0; i-- {
+			addDepthRow(depthReport, "ASK", report.Asks[i])
+		}
+		for _, l := range report.Bids {
+			addDepthRow(depthReport, "BID", l)
+		}
+
+		reports := []*utils.Report{spreadReport, depthReport}
+		if len(report.Impact) > 0 {
+			impactReport := &utils.Report{Headers: []string{"SIDE", "SIZE", "FILLED", "AVG_PRICE", "WORST_PRICE", "SLIPPAGE_BPS"}}
+			for _, e := range report.Impact {
+				impactReport.AddRow(
+					e.Side,
+					utils.FormatAmount(e.Size),
+					utils.FormatRounded(e.Filled, 8),
+					utils.FormatRounded(e.AvgPrice, 8),
+					utils.FormatAmount(e.WorstPrice),
+					utils.FormatRounded(e.SlippageBps, 2),
+				)
+			}
+			reports = append(reports, impactReport)
+		}
+
+		return utils.PrintReports(cmd, report, reports...)
+	},
+}
+
+func limitDepth(levels []*utils.DepthLevel, depth int) []*utils.DepthLevel {
+	if depth > 0 && len(levels) > depth {
+		return levels[:depth]
+	}
+	return levels
+}
+
+func addDepthRow(report *utils.Report, side string, l *utils.DepthLevel) {
+	report.AddRow(
+		side,
+		utils.FormatAmount(l.Price),
+		utils.FormatRounded(l.Size, 8),
+		utils.FormatRounded(l.CumulativeSize, 8),
+		utils.FormatRounded(l.CumulativeNotional, 2),
+	)
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: bookCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.InstrumentIdFlag,
+					Shorthand:    "i",
+					Usage:        "ID of the Instrument, e.g. BTC-PERP (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.GroupFlag,
+					Shorthand:    "g",
+					Usage:        "Group levels into price buckets of this size, e.g. 10. Levels are not grouped if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.DepthFlag,
+					Shorthand:    "d",
+					Usage:        "Number of levels shown per side. 0 shows all",
+					DefaultValue: 10,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ImpactFlag,
+					Shorthand:    "",
+					Usage:        "Comma separated order sizes in base units to estimate market impact for",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -25,8 +25,9 @@ import (
 )
 
 var cancelOrderCmd = &cobra.Command{
-	Use:   "cancel-order",
-	Short: "Attempt to cancel an open order.",
+	Use:         "cancel-order",
+	Short:       "Attempt to cancel an open order.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -47,6 +48,7 @@ var cancelOrderCmd = &cobra.Command{
 		}
 
 		response, err := client.CancelOrder(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot cancel order: %w", err)
 		}
@@ -25,8 +25,9 @@ import (
 )
 
 var cancelOrdersCmd = &cobra.Command{
-	Use:   "cancel-orders",
-	Short: "Attempt to cancel all open orders.",
+	Use:         "cancel-orders",
+	Short:       "Attempt to cancel all open orders.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -47,6 +48,7 @@ var cancelOrdersCmd = &cobra.Command{
 		}
 
 		response, err := client.CancelOrders(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot cancel orders: %w", err)
 		}
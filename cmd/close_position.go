@@ -0,0 +1,360 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+var closePositionCmd = &cobra.Command{
+	Use:   "close-position",
+	Short: "Close all or part of a position with a close-only order.",
+	Long: "Close all or part of a position with a close-only order.\n\n" +
+		"The side and size are derived from the current position and the size is rounded down to the base increment. " +
+		"LIMIT orders are priced at the best price on their side of the book, moved --offset-ticks away from the spread, " +
+		"unless --limit-price is given.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		pct, err := utils.ParsePercent(utils.GetFlagStringValue(cmd, utils.PctFlag))
+		if err != nil {
+			return err
+		}
+		if pct <= 0 || pct > 1 {
+			return fmt.Errorf("invalid --%s: must be more than 0%% and at most 100%%", utils.PctFlag)
+		}
+
+		position, err := getInstrumentPosition(client, portfolioId, utils.GetFlagStringValue(cmd, utils.InstrumentIdFlag))
+		if err != nil {
+			return err
+		}
+
+		request, err := positionOrderRequest(cmd, client, portfolioId, position, math.Abs(utils.PositionSize(position))*pct)
+		if err != nil {
+			return err
+		}
+		return submitPositionOrder(cmd, client, request, true)
+	},
+}
+
+func getInstrumentPosition(client *intx.Client, portfolioId, instrument string) (intx.Position, error) {
+	positions, err := utils.GetPositions(client, portfolioId)
+	if err != nil {
+		return intx.Position{}, err
+	}
+	return utils.FindPosition(positions, instrument)
+}
+
+// positionOrderRequest builds an order against position from the order flags
+// shared by close-position and flip-position.
+func positionOrderRequest(cmd *cobra.Command, client *intx.Client, portfolioId string, position intx.Position, size float64) (*intx.CreateOrderRequest, error) {
+	symbol := utils.PositionInstrument(position)
+	instrument, err := utils.GetInstrument(client, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	roundedSize := utils.RoundToIncrement(size, instrument.BaseIncrement, false)
+	if utils.ParseAmountOrZero(roundedSize) <= 0 {
+		return nil, fmt.Errorf("size %s is below the base increment %s", utils.FormatAmount(size), instrument.BaseIncrement)
+	}
+
+	clientOrderId := utils.GetFlagStringValue(cmd, utils.ClientOrderIdFlag)
+	if clientOrderId == "" {
+		clientOrderId = uuid.New().String()
+	}
+
+	request := &intx.CreateOrderRequest{
+		ClientOrderId: clientOrderId,
+		PortfolioId:   portfolioId,
+		InstrumentId:  symbol,
+		Side:          utils.ClosingSide(position),
+		Size:          roundedSize,
+		Tif:           strings.ToUpper(utils.GetFlagStringValue(cmd, utils.TifFlag)),
+		Type:          strings.ToUpper(utils.GetFlagStringValue(cmd, utils.TypeFlag)),
+		Price:         utils.GetFlagStringValue(cmd, utils.LimitPriceFlag),
+	}
+	if cmd.Flags().Changed(utils.PostOnlyFlag) {
+		request.PostOnly = utils.GetFlagBoolValue(cmd, utils.PostOnlyFlag)
+	}
+
+	ticks, _ := cmd.Flags().GetInt(utils.OffsetTicksFlag)
+	switch request.Type {
+	case utils.OrderTypeMarket:
+		if ticks != 0 {
+			return nil, fmt.Errorf("--%s only applies to LIMIT orders", utils.OffsetTicksFlag)
+		}
+		if request.Tif == "" {
+			request.Tif = utils.TifIoc
+		}
+	case utils.OrderTypeLimit:
+		if request.Price == "" {
+			quote, err := utils.GetQuote(client, symbol)
+			if err != nil {
+				return nil, err
+			}
+			if request.Price, err = utils.PassivePrice(request.Side, quote, instrument, ticks); err != nil {
+				return nil, err
+			}
+		} else if ticks != 0 {
+			return nil, fmt.Errorf("--%s and --%s cannot be combined", utils.OffsetTicksFlag, utils.LimitPriceFlag)
+		}
+		if request.Tif == "" {
+			request.Tif = utils.TifGtc
+		}
+	default:
+		return nil, fmt.Errorf("invalid order type %s: must be %s or %s", request.Type, utils.OrderTypeMarket, utils.OrderTypeLimit)
+	}
+
+	if err := utils.ValidateCreateOrderRequest(request); err != nil {
+		return nil, fmt.Errorf("invalid order: %w", err)
+	}
+	return request, nil
+}
+
+// submitPositionOrder places request, close-only if asked to, and prints the
+// order, or with --wait the order once it is final.
+func submitPositionOrder(cmd *cobra.Command, client *intx.Client, request *intx.CreateOrderRequest, closeOnly bool) error {
+	ctx, cancel := utils.GetContextWithTimeout()
+	defer cancel()
+
+	var order *intx.Order
+	var err error
+	if closeOnly {
+		order, err = utils.CreateCloseOrder(ctx, client, request)
+		utils.RecordAudit(cmd, request, order, err)
+	} else {
+		var response *intx.CreateOrderResponse
+		response, err = client.CreateOrder(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
+		if err == nil {
+			order = response.Order
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create order: %w", err)
+	}
+	if order == nil {
+		return errors.New("no order returned")
+	}
+
+	if wait, _ := cmd.Flags().GetBool(utils.WaitFlag); !wait {
+		return utils.PrintJsonResponse(cmd, &intx.CreateOrderResponse{Order: order, Request: request})
+	}
+
+	timeout, err := utils.GetFlagDurationValue(cmd, utils.TimeoutFlag)
+	if err != nil {
+		return err
+	}
+	interval, err := utils.GetFlagDurationValue(cmd, utils.IntervalFlag)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("--%s must be positive", utils.IntervalFlag)
+	}
+
+	order, err = waitForOrder(client, request.PortfolioId, order, timeout, interval, os.Stderr)
+	if printErr := utils.PrintJsonResponse(cmd, order); printErr != nil && err == nil {
+		err = printErr
+	}
+	if err != nil {
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+// waitForOrder polls order until it is final, printing changes to out. It
+// fails with exit status 2 when the order ends unfilled or partially filled
+// and 3 on timeout. Errors while polling are reported and retried until the
+// base context of API calls is done.
+func waitForOrder(client *intx.Client, portfolioId string, order *intx.Order, timeout, interval time.Duration, out io.Writer) (*intx.Order, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	ctx := utils.BaseContext()
+	fmt.Fprintln(out, formatOrderProgress(order))
+	for !utils.IsOrderFinal(order.OrderStatus) {
+		wait := interval
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return order, &utils.ExitError{
+					Code: utils.ExitCodeTimeout,
+					Err:  fmt.Errorf("timed out waiting for order %s: %s", order.OrderId, order.OrderStatus),
+				}
+			}
+			wait = min(wait, remaining)
+		}
+		if err := utils.Sleep(ctx, wait); err != nil {
+			return order, fmt.Errorf("stopped waiting for order %s: %w", order.OrderId, err)
+		}
+
+		current, err := utils.GetOrder(client, portfolioId, order.OrderId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return order, err
+			}
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+			continue
+		}
+		if current.OrderStatus != order.OrderStatus || current.ExecQty != order.ExecQty {
+			fmt.Fprintln(out, formatOrderProgress(current))
+		}
+		order = current
+	}
+
+	if !strings.EqualFold(order.OrderStatus, utils.OrderStatusFilled) {
+		return order, &utils.ExitError{
+			Code: utils.ExitCodeFailed,
+			Err:  fmt.Errorf("order %s is %s with %s of %s filled", order.OrderId, order.OrderStatus, utils.FormatAmount(utils.ParseAmountOrZero(order.ExecQty)), order.Size),
+		}
+	}
+	return order, nil
+}
+
+func formatOrderProgress(order *intx.Order) string {
+	line := fmt.Sprintf("%s  %s %s", time.Now().UTC().Format(time.RFC3339), order.OrderId, order.OrderStatus)
+	if utils.ParseAmountOrZero(order.ExecQty) > 0 {
+		line += fmt.Sprintf("  filled %s of %s at %s", order.ExecQty, order.Size, order.AvgPrice)
+	}
+	return line
+}
+
+func positionOrderFlagConfigs() []utils.FlagConfig {
+	return []utils.FlagConfig{
+		{
+			FlagName:     utils.InstrumentIdFlag,
+			Shorthand:    "i",
+			Usage:        "Instrument of the position, e.g. BTC-PERP (Required)",
+			DefaultValue: "",
+			Required:     true,
+		},
+		{
+			FlagName:     utils.PortfolioIdFlag,
+			Shorthand:    "p",
+			Usage:        "Portfolio ID. Uses environment variable if blank",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.TypeFlag,
+			Shorthand:    "t",
+			Usage:        "Order type: MARKET or LIMIT",
+			DefaultValue: utils.OrderTypeMarket,
+			Required:     false,
+		},
+		{
+			FlagName:     utils.TifFlag,
+			Shorthand:    "f",
+			Usage:        "Time in force. Defaults to IOC for MARKET and GTC for LIMIT orders",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.LimitPriceFlag,
+			Shorthand:    "l",
+			Usage:        "Limit price. Derived from the quote and --offset-ticks if blank",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.OffsetTicksFlag,
+			Shorthand:    "",
+			Usage:        "Price increments between a LIMIT order and the best price on its side of the book. Negative values cross the spread",
+			DefaultValue: 0,
+			Required:     false,
+		},
+		{
+			FlagName:     utils.PostOnlyFlag,
+			Shorthand:    "o",
+			Usage:        "Post only mode bool for order",
+			DefaultValue: false,
+			Required:     false,
+		},
+		{
+			FlagName:     utils.ClientOrderIdFlag,
+			Shorthand:    "c",
+			Usage:        "Client order id value. Autogenerated if blank",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.WaitFlag,
+			Shorthand:    "w",
+			Usage:        "Poll until the order is final. Exit status is 0 when filled, 2 otherwise and 3 on timeout",
+			DefaultValue: false,
+			Required:     false,
+		},
+		{
+			FlagName:     utils.TimeoutFlag,
+			Shorthand:    "",
+			Usage:        "Give up waiting after this duration. 0 waits indefinitely",
+			DefaultValue: "5m",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.IntervalFlag,
+			Shorthand:    "",
+			Usage:        "Poll interval while waiting",
+			DefaultValue: "1s",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.FormatFlag,
+			Shorthand:    "z",
+			Usage:        "Pass true for formatted JSON. Default is false",
+			DefaultValue: false,
+			Required:     false,
+		},
+	}
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: closePositionCmd,
+			FlagConfig: append(positionOrderFlagConfigs(),
+				utils.FlagConfig{
+					FlagName:     utils.PctFlag,
+					Shorthand:    "",
+					Usage:        "Share of the position to close, e.g. 50",
+					DefaultValue: "100",
+					Required:     false,
+				},
+			),
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -24,8 +24,9 @@ import (
 )
 
 var createCounterPartyIdCmd = &cobra.Command{
-	Use:   "create-counterparty-id",
-	Short: "Create new counterparty ID.",
+	Use:         "create-counterparty-id",
+	Short:       "Create new counterparty ID.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -40,6 +41,7 @@ var createCounterPartyIdCmd = &cobra.Command{
 		}
 
 		response, err := client.CreateCounterpartyId(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot create counterparty ID: %w", err)
 		}
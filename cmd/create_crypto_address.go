@@ -21,11 +21,13 @@ import (
 	"github.com/coinbase-samples/intx-cli/utils"
 	"github.com/coinbase-samples/intx-sdk-go"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 var createCryptoAddressCmd = &cobra.Command{
-	Use:   "create-crypto-address",
-	Short: "Create crypto address.",
+	Use:         "create-crypto-address",
+	Short:       "Create crypto address.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -52,10 +54,27 @@ var createCryptoAddressCmd = &cobra.Command{
 		}
 
 		response, err := client.CreateCryptoAddress(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot create address: %w", err)
 		}
 
+		if response.Address != nil && response.Address.Address != "" {
+			if response.Address.NetworkArnId != "" {
+				networkArnId = response.Address.NetworkArnId
+			}
+			err := utils.SaveDepositAddress(&utils.DepositAddress{
+				PortfolioId:  portfolioId,
+				AssetId:      assetId,
+				NetworkArnId: networkArnId,
+				Address:      response.Address.Address,
+				Label:        utils.GetFlagStringValue(cmd, utils.LabelFlag),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: cannot record deposit address: %v\n", err)
+			}
+		}
+
 		return utils.PrintJsonResponse(cmd, response)
 	},
 }
@@ -79,6 +98,13 @@ func init() {
 					DefaultValue: "",
 					Required:     true,
 				},
+				{
+					FlagName:     utils.LabelFlag,
+					Shorthand:    "l",
+					Usage:        "Label recorded with the address in deposit-addresses",
+					DefaultValue: "",
+					Required:     false,
+				},
 				{
 					FlagName:     utils.PortfolioIdFlag,
 					Shorthand:    "i",
@@ -22,11 +22,14 @@ import (
 	"github.com/coinbase-samples/intx-sdk-go"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"os"
+	"strings"
 )
 
 var createOrderCmd = &cobra.Command{
-	Use:   "create-order",
-	Short: "Create an order.",
+	Use:         "create-order",
+	Short:       "Create an order.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -38,9 +41,6 @@ var createOrderCmd = &cobra.Command{
 			clientOrderId = uuid.New().String()
 		}
 
-		ctx, cancel := utils.GetContextWithTimeout()
-		defer cancel()
-
 		request := &intx.CreateOrderRequest{
 			ClientOrderId: clientOrderId,
 			PortfolioId:   portfolioId,
@@ -54,10 +54,30 @@ var createOrderCmd = &cobra.Command{
 			ExpireTime:    utils.StringPtr(utils.GetFlagStringValue(cmd, utils.ExpiryTimeFlag)),
 			UserId:        utils.StringPtr(utils.GetFlagStringValue(cmd, utils.UserIdFlag)),
 			StpMode:       utils.StringPtr(utils.GetFlagStringValue(cmd, utils.StpModeFlag)),
-			PostOnly:      utils.GetFlagBoolValue(cmd, utils.PostOnlyFlag),
 		}
+		if cmd.Flags().Changed(utils.PostOnlyFlag) {
+			request.PostOnly = utils.GetFlagBoolValue(cmd, utils.PostOnlyFlag)
+		}
+
+		if request.Size, err = resolveOrderSize(cmd, client, request); err != nil {
+			return err
+		}
+		if risk := utils.GetFlagStringValue(cmd, utils.RiskFlag); risk != "" {
+			fmt.Fprintf(os.Stderr, "NOTE: --%s %s is only used for sizing; no stop is placed with this order, place the protective stop separately\n", utils.StopPriceFlag, *request.StopPrice)
+			request.StopPrice = nil
+		}
+
+		if err := utils.ValidateCreateOrderRequest(request); err != nil {
+			return fmt.Errorf("invalid order: %w", err)
+		}
+
+		// Created after sizing, whose API calls would otherwise use up the
+		// timeout of the order request.
+		ctx, cancel := utils.GetContextWithTimeout()
+		defer cancel()
 
 		response, err := client.CreateOrder(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot create order: %w", err)
 		}
@@ -66,6 +86,111 @@ var createOrderCmd = &cobra.Command{
 	},
 }
 
+// resolveOrderSize returns --size, or the size derived from --notional,
+// --pct-balance or --risk rounded down to the base increment. The entry
+// price is the limit price, or the touch of the current quote.
+func resolveOrderSize(cmd *cobra.Command, client *intx.Client, request *intx.CreateOrderRequest) (string, error) {
+	sizing := &utils.OrderSizing{}
+	given := []string{}
+	if request.Size != "" {
+		given = append(given, utils.SizeFlag)
+	}
+	for _, f := range []struct {
+		name  string
+		value *float64
+	}{
+		{utils.NotionalFlag, &sizing.Notional},
+		{utils.PctBalanceFlag, &sizing.PctBalance},
+		{utils.RiskFlag, &sizing.Risk},
+	} {
+		value := utils.GetFlagStringValue(cmd, f.name)
+		if value == "" {
+			continue
+		}
+		given = append(given, f.name)
+
+		var err error
+		if f.name == utils.PctBalanceFlag {
+			*f.value, err = utils.ParsePercent(value)
+		} else {
+			*f.value, err = utils.ParseAmount(value)
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid --%s: %w", f.name, err)
+		}
+		if *f.value <= 0 {
+			return "", fmt.Errorf("--%s must be positive", f.name)
+		}
+	}
+
+	switch {
+	case len(given) == 0:
+		return "", fmt.Errorf("one of --%s, --%s, --%s or --%s is required", utils.SizeFlag, utils.NotionalFlag, utils.PctBalanceFlag, utils.RiskFlag)
+	case len(given) > 1:
+		return "", fmt.Errorf("only one of --%s can be given", strings.Join(given, ", --"))
+	case request.Size != "":
+		return request.Size, nil
+	}
+
+	side := strings.ToUpper(request.Side)
+	if sizing.Risk > 0 {
+		switch strings.ToUpper(request.Type) {
+		case utils.OrderTypeStop, utils.OrderTypeStopLimit:
+			return "", fmt.Errorf("--%s uses --%s as the protective stop and cannot size %s orders", utils.RiskFlag, utils.StopPriceFlag, request.Type)
+		}
+		if request.StopPrice == nil {
+			return "", fmt.Errorf("--%s requires --%s", utils.RiskFlag, utils.StopPriceFlag)
+		}
+		stopPrice, err := utils.ParseAmount(*request.StopPrice)
+		if err != nil {
+			return "", fmt.Errorf("invalid --%s: %w", utils.StopPriceFlag, err)
+		}
+		sizing.StopPrice = stopPrice
+	}
+
+	instrument, err := utils.GetInstrument(client, request.InstrumentId)
+	if err != nil {
+		return "", err
+	}
+
+	price, err := utils.ParseAmount(request.Price)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%s: %w", utils.LimitPriceFlag, err)
+	}
+	if price <= 0 {
+		quote, err := utils.GetQuote(client, request.InstrumentId)
+		if err != nil {
+			return "", err
+		}
+		if price, err = utils.TouchPrice(side, quote); err != nil {
+			return "", err
+		}
+	}
+
+	collateral := 0.0
+	if sizing.PctBalance > 0 {
+		ctx, cancel := utils.GetContextWithTimeout()
+		defer cancel()
+		response, err := client.GetPortfolioBalances(ctx, &intx.GetPortfolioBalancesRequest{PortfolioId: request.PortfolioId})
+		if err != nil {
+			return "", fmt.Errorf("cannot get balances: %w", err)
+		}
+		collateral = utils.AvailableCollateral(response.Balances)
+	}
+
+	size, err := sizing.Size(side, price, collateral)
+	if err != nil {
+		return "", fmt.Errorf("cannot size order: %w", err)
+	}
+	rounded := utils.RoundToIncrement(size, instrument.BaseIncrement, false)
+	if utils.ParseAmountOrZero(rounded) <= 0 {
+		return "", fmt.Errorf("size %s is below the base increment %s", utils.FormatAmount(size), instrument.BaseIncrement)
+	}
+
+	fmt.Fprintf(os.Stderr, "Sized %s %s at an entry price of %s\n", rounded, request.InstrumentId, utils.FormatAmount(price))
+	return rounded, nil
+}
+
 func init() {
 	cmdConfigs := []utils.CommandConfig{
 		{
@@ -88,9 +213,30 @@ func init() {
 				{
 					FlagName:     utils.SizeFlag,
 					Shorthand:    "b",
-					Usage:        "Order size in base asset units (Required)",
+					Usage:        "Order size in base asset units. Required unless sized with --notional, --pct-balance or --risk",
 					DefaultValue: "",
-					Required:     true,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.NotionalFlag,
+					Shorthand:    "",
+					Usage:        "Size the order to this amount of the quote asset at the entry price",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.PctBalanceFlag,
+					Shorthand:    "",
+					Usage:        "Size the order to this percentage of available collateral, e.g. 10",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.RiskFlag,
+					Shorthand:    "",
+					Usage:        "Size the order so that a move from the entry price to --stop-price loses this amount. The stop is not placed",
+					DefaultValue: "",
+					Required:     false,
 				},
 				{
 					FlagName:     utils.TifFlag,
@@ -116,7 +262,7 @@ func init() {
 				{
 					FlagName:     utils.StopPriceFlag,
 					Shorthand:    "p",
-					Usage:        "Stop price for the order",
+					Usage:        "Stop price for the order, or with --risk the stop price to size against, which is not sent",
 					DefaultValue: "",
 					Required:     false,
 				},
@@ -145,7 +291,7 @@ func init() {
 					FlagName:     utils.PostOnlyFlag,
 					Shorthand:    "o",
 					Usage:        "Post only mode bool for order",
-					DefaultValue: "",
+					DefaultValue: false,
 					Required:     false,
 				},
 				{
@@ -24,8 +24,9 @@ import (
 )
 
 var createPortfolioCmd = &cobra.Command{
-	Use:   "create-portfolio",
-	Short: "Create a new portfolio.",
+	Use:         "create-portfolio",
+	Short:       "Create a new portfolio.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, _, err := utils.InitClientAndPortfolioId(cmd, false)
 		if err != nil {
@@ -40,6 +41,7 @@ var createPortfolioCmd = &cobra.Command{
 		}
 
 		response, err := client.CreatePortfolio(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot create portfolio: %w", err)
 		}
@@ -24,8 +24,9 @@ import (
 )
 
 var createPortfolioTransferCmd = &cobra.Command{
-	Use:   "create-portfolio-transfer",
-	Short: "Create a new transfer.",
+	Use:         "create-portfolio-transfer",
+	Short:       "Create a new transfer.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -43,6 +44,7 @@ var createPortfolioTransferCmd = &cobra.Command{
 		}
 
 		response, err := client.CreatePortfolioTransfer(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot create transfer: %w", err)
 		}
@@ -77,6 +79,13 @@ func init() {
 					DefaultValue: "",
 					Required:     true,
 				},
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Source portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
 				{
 					FlagName:     utils.FormatFlag,
 					Shorthand:    "z",
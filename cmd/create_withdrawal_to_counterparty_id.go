@@ -24,27 +24,55 @@ import (
 )
 
 var createWithdrawalToCounterPartyIdCmd = &cobra.Command{
-	Use:   "create-withdrawal-to-counterparty-id",
-	Short: "Create a withdrawal to counterparty id.",
+	Use:         "create-withdrawal-to-counterparty-id",
+	Short:       "Create a withdrawal to counterparty id.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
 			return fmt.Errorf("cannot initialize from environment: %w", err)
 		}
 
+		destination, err := utils.ResolveCounterpartyDestination(cmd)
+		if err != nil {
+			return fmt.Errorf("cannot resolve destination: %w", err)
+		}
+
+		assetId := utils.GetFlagStringValue(cmd, utils.AssetIdFlag)
+		if assetId == "" {
+			assetId = destination.AssetId
+		}
+		if assetId == "" {
+			return fmt.Errorf("%s is required", utils.AssetIdFlag)
+		}
+
+		amount, err := utils.ParseAmount(utils.GetFlagStringValue(cmd, utils.AmountFlag))
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+
+		reservation := utils.NewWithdrawalReservationId()
+		if _, _, err := utils.ReserveWithdrawal(reservation, assetId, amount, destination.CounterpartyId); err != nil {
+			return fmt.Errorf("withdrawal check failed: %w", err)
+		}
+
 		ctx, cancel := utils.GetContextWithTimeout()
 		defer cancel()
 
 		request := &intx.CreateWithdrawalToCounterpartyIdRequest{
 			PortfolioId:    portfolioId,
-			CounterpartyId: utils.GetFlagStringValue(cmd, utils.CounterpartyIdFlag),
-			AssetId:        utils.GetFlagStringValue(cmd, utils.AssetIdFlag),
+			CounterpartyId: destination.CounterpartyId,
+			AssetId:        assetId,
 			Amount:         utils.GetFlagStringValue(cmd, utils.AmountFlag),
 			Nonce:          utils.GetFlagStringValue(cmd, utils.NonceFlag),
 		}
 
 		response, err := client.CreateWithdrawalToCounterpartyId(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
+			if utils.IsRejectedRequest(err) {
+				releaseWithdrawal(reservation)
+			}
 			return fmt.Errorf("cannot create withdrawal: %w", err)
 		}
 
@@ -60,16 +88,22 @@ func init() {
 				{
 					FlagName:     utils.CounterpartyIdFlag,
 					Shorthand:    "c",
-					Usage:        "ID of counterparty (Required)",
+					Usage:        "ID of counterparty (Required unless --to-name is set)",
 					DefaultValue: "",
-					Required:     true,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ToNameFlag,
+					Usage:        "Name of an address book counterparty to withdraw to",
+					DefaultValue: "",
+					Required:     false,
 				},
 				{
 					FlagName:     utils.AssetIdFlag,
 					Shorthand:    "i",
-					Usage:        "ID of asset to be withdrawn (Required)",
+					Usage:        "ID of asset to be withdrawn. Uses the address book entry asset if blank",
 					DefaultValue: "",
-					Required:     true,
+					Required:     false,
 				},
 				{
 					FlagName:     utils.AmountFlag,
@@ -85,6 +119,13 @@ func init() {
 					DefaultValue: "",
 					Required:     false,
 				},
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
 				{
 					FlagName:     utils.FormatFlag,
 					Shorthand:    "z",
@@ -17,23 +17,32 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/coinbase-samples/intx-cli/utils"
 	"github.com/coinbase-samples/intx-sdk-go"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 var createWithdrawalToCryptoAddressCmd = &cobra.Command{
-	Use:   "create-withdrawal-to-crypto-address",
-	Short: "Create a withdrawal to crypto address.",
+	Use:         "create-withdrawal-to-crypto-address",
+	Short:       "Create a withdrawal to crypto address.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
 			return fmt.Errorf("cannot initialize from environment: %w", err)
 		}
 
-		ctx, cancel := utils.GetContextWithTimeout()
-		defer cancel()
+		destination, err := utils.ResolveCryptoDestination(cmd)
+		if err != nil {
+			return fmt.Errorf("cannot resolve destination: %w", err)
+		}
+		if destination.Memo != "" {
+			return fmt.Errorf("cannot withdraw to %s: it requires memo %s, which withdrawal requests cannot include", destination.Name, destination.Memo)
+		}
 
 		addNetworkFeeToTotalPtr := utils.GetFlagBoolValue(cmd, utils.AddNetworkFeeToTotalFlag)
 		addNetworkFeeToTotal := false
@@ -43,23 +52,115 @@ var createWithdrawalToCryptoAddressCmd = &cobra.Command{
 
 		request := &intx.CreateWithdrawalToCryptoAddressRequest{
 			PortfolioId:          portfolioId,
-			AssetId:              utils.GetFlagStringValue(cmd, utils.AssetIdFlag),
+			AssetId:              destination.AssetId,
 			Amount:               utils.GetFlagStringValue(cmd, utils.AmountFlag),
 			AddNetworkFeeToTotal: addNetworkFeeToTotal,
-			NetworkArnId:         utils.GetFlagStringValue(cmd, utils.NetworkArnIdFlag),
-			Address:              utils.GetFlagStringValue(cmd, utils.AddressFlag),
-			Nonce:                utils.GetFlagStringValue(cmd, utils.NonceFlag),
+			NetworkArnId:         destination.NetworkArnId,
+			Address:              destination.Address,
+		}
+
+		reference := utils.GetFlagStringValue(cmd, utils.ReferenceFlag)
+		key := utils.WithdrawalKey(reference, request)
+		allowDuplicate := utils.GetFlagBoolValue(cmd, utils.AllowDuplicateFlag)
+
+		attempt, err := utils.ReserveWithdrawalAttempt(key, reference, utils.GetFlagStringValue(cmd, utils.NonceFlag), *allowDuplicate, request)
+		if err != nil {
+			return fmt.Errorf("cannot reserve withdrawal nonce: %w", err)
 		}
+		request.Nonce = attempt.Nonce
+
+		if attempt.Status == utils.WithdrawalAttemptSubmitted {
+			return duplicateWithdrawalError(attempt)
+		}
+
+		checkCtx, cancelCheck := utils.GetContextWithTimeout()
+		check, err := utils.CheckCryptoWithdrawal(checkCtx, client, destination, request.Amount, addNetworkFeeToTotal)
+		cancelCheck()
+		if err != nil {
+			return fmt.Errorf("withdrawal check failed: %w", err)
+		}
+
+		if attempt.Tries > 0 {
+			transfer, err := utils.FindSubmittedWithdrawal(client, portfolioId, attempt, check.NetworkName)
+			if err != nil {
+				return fmt.Errorf("cannot check for a previous withdrawal attempt: %w", err)
+			}
+			if transfer != nil {
+				attempt.Status = utils.WithdrawalAttemptSubmitted
+				attempt.TransferUuid = transfer.TransferUuid
+				if attempt.Response, err = json.Marshal(transfer); err != nil {
+					return fmt.Errorf("cannot marshal transfer: %w", err)
+				}
+				if err := utils.SaveWithdrawalAttempt(attempt); err != nil {
+					return fmt.Errorf("cannot save withdrawal attempt: %w", err)
+				}
+				return duplicateWithdrawalError(attempt)
+			}
+		}
+
+		// Retries reuse the nonce and so replace their own earlier reservation.
+		reservation := attempt.Key + ":" + attempt.Nonce
+		limit, used, err := utils.ReserveWithdrawal(reservation, request.AssetId, check.Debit(), request.Address)
+		if err != nil {
+			return fmt.Errorf("withdrawal check failed: %w", err)
+		}
+		if limit > 0 {
+			check.DailyLimit = utils.FormatAmount(limit)
+			check.WithdrawnToday = utils.FormatAmount(used)
+		}
+		fmt.Fprintln(os.Stderr, check.Describe())
+
+		attempt.Tries++
+		if err := utils.SaveWithdrawalAttempt(attempt); err != nil {
+			releaseWithdrawal(reservation)
+			return fmt.Errorf("cannot save withdrawal attempt: %w", err)
+		}
+
+		ctx, cancel := utils.GetContextWithTimeout()
+		defer cancel()
 
 		response, err := client.CreateWithdrawalToCryptoAddress(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
+			if utils.IsRejectedRequest(err) {
+				releaseWithdrawal(reservation)
+			}
 			return fmt.Errorf("cannot create withdrawal: %w", err)
 		}
 
+		attempt.Status = utils.WithdrawalAttemptSubmitted
+		if attempt.Response, err = json.Marshal(response); err == nil {
+			err = utils.SaveWithdrawalAttempt(attempt)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: cannot save withdrawal attempt: %v\n", err)
+		}
+
 		return utils.PrintJsonResponse(cmd, response)
 	},
 }
 
+// duplicateWithdrawalError refuses to resend a withdrawal that was already
+// submitted, showing the earlier result.
+func duplicateWithdrawalError(attempt *utils.WithdrawalAttempt) error {
+	var earlier bytes.Buffer
+	if json.Compact(&earlier, attempt.Response) == nil {
+		fmt.Fprintf(os.Stderr, "Earlier result: %s\n", earlier.String())
+	}
+	if attempt.Reference != "" {
+		return fmt.Errorf("withdrawal with reference %s was already submitted at %s with nonce %s: use a new reference to withdraw again", attempt.Reference, attempt.CreatedAt, attempt.Nonce)
+	}
+	return fmt.Errorf("an identical withdrawal was already submitted at %s with nonce %s: pass --%s to send it again", attempt.CreatedAt, attempt.Nonce, utils.AllowDuplicateFlag)
+}
+
+// releaseWithdrawal frees the daily limit reserved for a withdrawal that was
+// not executed.
+func releaseWithdrawal(reservation string) {
+	if err := utils.ReleaseWithdrawal(reservation); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: cannot release withdrawal from daily limits: %v\n", err)
+	}
+}
+
 func init() {
 	cmdConfigs := []utils.CommandConfig{
 		{
@@ -68,9 +169,9 @@ func init() {
 				{
 					FlagName:     utils.AssetIdFlag,
 					Shorthand:    "i",
-					Usage:        "ID of asset to be withdrawn (Required)",
+					Usage:        "ID of asset to be withdrawn (Required unless --to-name is set)",
 					DefaultValue: "",
-					Required:     true,
+					Required:     false,
 				},
 				{
 					FlagName:     utils.AmountFlag,
@@ -82,16 +183,55 @@ func init() {
 				{
 					FlagName:     utils.NetworkArnIdFlag,
 					Shorthand:    "n",
-					Usage:        "Network Arn Id for withdrawal (Required)",
+					Usage:        "Network Arn Id for withdrawal (Required unless --to-name is set)",
 					DefaultValue: "",
-					Required:     true,
+					Required:     false,
 				},
 				{
 					FlagName:     utils.AddressFlag,
 					Shorthand:    "d",
-					Usage:        "Address for withdrawal (Required)",
+					Usage:        "Address for withdrawal (Required unless --to-name is set)",
 					DefaultValue: "",
-					Required:     true,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AddNetworkFeeToTotalFlag,
+					Shorthand:    "f",
+					Usage:        "Pass true to add the network fee on top of the amount",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ReferenceFlag,
+					Shorthand:    "r",
+					Usage:        "Unique reference of the withdrawal. Retries with the same reference reuse its nonce",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AllowDuplicateFlag,
+					Usage:        "Pass true to send the withdrawal even if an identical one without a reference was submitted in the last 24 hours",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.NonceFlag,
+					Usage:        "Nonce for withdrawal. Generated and persisted per withdrawal if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ToNameFlag,
+					Usage:        "Name of an address book destination to withdraw to",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
 				},
 				{
 					FlagName:     utils.FormatFlag,
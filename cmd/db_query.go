@@ -0,0 +1,98 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var dbQueryCmd = &cobra.Command{
+	Use:   "query SQL",
+	Short: "Run a read-only SQL query against the local journal.",
+	Long: "Run a read-only SQL query against the local journal.\n\n" +
+		"Tables: portfolios, orders, fills, transfers, positions, balances and sync_state. " +
+		"Each table has typed columns for common fields and a data column with the full API record as JSON.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := utils.GetJournalPath(utils.GetFlagStringValue(cmd, utils.DbFlag))
+		if err != nil {
+			return err
+		}
+		db, err := utils.OpenJournalReadOnly(path)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		result, err := utils.QueryJournal(db, args[0])
+		if err != nil {
+			return fmt.Errorf("cannot run query: %w", err)
+		}
+
+		records := make([]map[string]interface{}, 0, len(result.Rows))
+		report := &utils.Report{Headers: result.Columns}
+		for _, row := range result.Rows {
+			record := map[string]interface{}{}
+			values := make([]string, len(row))
+			for i, v := range row {
+				record[result.Columns[i]] = v
+				if v != nil {
+					values[i] = fmt.Sprint(v)
+				}
+			}
+			records = append(records, record)
+			report.AddRow(values...)
+		}
+		report.Value = records
+
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: dbQueryCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.DbFlag,
+					Usage:        "Journal database file. Defaults to journal.db in the CLI home directory",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(dbCmd, cmdConfigs)
+}
@@ -0,0 +1,101 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var depositAddressesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded deposit addresses, newest first.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addresses, err := utils.LoadDepositAddresses()
+		if err != nil {
+			return fmt.Errorf("cannot load deposit addresses: %w", err)
+		}
+
+		matches := addresses.Filter(
+			utils.GetFlagStringValue(cmd, utils.PortfolioIdFlag),
+			utils.GetFlagStringValue(cmd, utils.AssetIdFlag),
+			utils.GetFlagStringValue(cmd, utils.NetworkArnIdFlag),
+		)
+
+		report := &utils.Report{
+			Value:   matches,
+			Headers: []string{"PORTFOLIO", "ASSET", "NETWORK", "ADDRESS", "LABEL", "CREATED"},
+		}
+		for _, a := range matches {
+			report.AddRow(a.PortfolioId, a.AssetId, a.NetworkArnId, a.Address, a.Label, a.CreatedAt)
+		}
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func depositAddressFilterFlagConfigs() []utils.FlagConfig {
+	return []utils.FlagConfig{
+		{
+			FlagName:     utils.PortfolioIdFlag,
+			Shorthand:    "p",
+			Usage:        "Only addresses of this portfolio ID",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.AssetIdFlag,
+			Shorthand:    "a",
+			Usage:        "Only addresses of this asset",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.NetworkArnIdFlag,
+			Shorthand:    "n",
+			Usage:        "Only addresses on this network ARN ID",
+			DefaultValue: "",
+			Required:     false,
+		},
+	}
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: depositAddressesListCmd,
+			FlagConfig: append(depositAddressFilterFlagConfigs(),
+				utils.FlagConfig{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				utils.FlagConfig{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			),
+		},
+	}
+
+	utils.RegisterCommandConfigs(depositAddressesCmd, cmdConfigs)
+}
@@ -0,0 +1,76 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/mdp/qrterminal/v3"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var depositAddressesQrCmd = &cobra.Command{
+	Use:   "qr [ADDRESS|LABEL]",
+	Short: "Render a recorded deposit address as a QR code in the terminal.",
+	Long: "Render a recorded deposit address as a QR code in the terminal.\n\n" +
+		"Without an argument, the newest address matching the portfolio, asset and network flags is shown.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addresses, err := utils.LoadDepositAddresses()
+		if err != nil {
+			return fmt.Errorf("cannot load deposit addresses: %w", err)
+		}
+
+		var address *utils.DepositAddress
+		if len(args) == 1 {
+			if address = addresses.Find(args[0]); address == nil {
+				return fmt.Errorf("no recorded deposit address or label %s", args[0])
+			}
+		} else {
+			matches := addresses.Filter(
+				utils.GetFlagStringValue(cmd, utils.PortfolioIdFlag),
+				utils.GetFlagStringValue(cmd, utils.AssetIdFlag),
+				utils.GetFlagStringValue(cmd, utils.NetworkArnIdFlag),
+			)
+			if len(matches) == 0 {
+				return errors.New("no recorded deposit address matches: create one with create-crypto-address")
+			}
+			address = matches[0]
+		}
+
+		qrterminal.GenerateHalfBlock(address.Address, qrterminal.M, os.Stdout)
+		fmt.Printf("\n%s\n%s on %s, portfolio %s", address.Address, address.AssetId, address.NetworkArnId, address.PortfolioId)
+		if address.Label != "" {
+			fmt.Printf(" (%s)", address.Label)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command:    depositAddressesQrCmd,
+			FlagConfig: depositAddressFilterFlagConfigs(),
+		},
+	}
+
+	utils.RegisterCommandConfigs(depositAddressesCmd, cmdConfigs)
+}
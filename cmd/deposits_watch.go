@@ -0,0 +1,151 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"time"
+)
+
+var depositsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll for incoming deposits and print new and completed ones.",
+	Long: "Poll for incoming deposits and print new and completed ones.\n\n" +
+		"Deposits created since --from (default 24 hours ago) that are still pending are reported on the first poll; " +
+		"deposits that had already completed are not. Runs until interrupted unless --timeout is set.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioIds, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		interval, err := utils.GetFlagDurationValue(cmd, utils.IntervalFlag)
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--%s must be positive", utils.IntervalFlag)
+		}
+		timeout, err := utils.GetFlagDurationValue(cmd, utils.TimeoutFlag)
+		if err != nil {
+			return err
+		}
+
+		from, err := utils.GetFlagTimeValue(cmd, utils.FromFlag)
+		if err != nil {
+			return err
+		}
+		if from.IsZero() {
+			from = time.Now().Add(-transferWatchLookback)
+		}
+
+		poll := func() ([]utils.Transfer, error) {
+			return utils.ListAllTransfers(client, utils.TransferFilter{
+				PortfolioIds: portfolioIds,
+				Type:         utils.TransferTypeDeposit,
+				TimeFrom:     from.UTC().Format(time.RFC3339),
+			})
+		}
+
+		return watchDeposits(interval, timeout, os.Stdout, poll)
+	},
+}
+
+// watchDeposits polls every interval until the timeout elapses, or forever
+// when it is 0. Deposits already final on the first poll are not reported.
+// Errors after the first poll are reported and retried.
+func watchDeposits(interval, timeout time.Duration, out io.Writer, poll func() ([]utils.Transfer, error)) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	tracker := utils.NewTransferTracker()
+
+	for first := true; ; first = false {
+		transfers, err := poll()
+		if err != nil && first {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+
+		for _, t := range transfers {
+			d := &utils.TransferDetails{Transfer: t}
+			if first && utils.IsTransferFinal(d.Status) {
+				tracker.Update(d)
+				continue
+			}
+			if previous, changed := tracker.Update(d); changed {
+				fmt.Fprintln(out, utils.FormatTransferTransition(previous, d))
+			}
+		}
+
+		wait := interval
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return nil
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: depositsWatchCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID(s). Uses environment variable if blank, supports comma-separated values for multiple IDs",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FromFlag,
+					Usage:        "Look for deposits created since this time (RFC3339 or YYYY-MM-DD). Defaults to 24 hours ago",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.IntervalFlag,
+					Usage:        "Poll interval, e.g. 30s",
+					DefaultValue: "30s",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TimeoutFlag,
+					Usage:        "Stop watching after this duration, e.g. 2h. 0 watches until interrupted",
+					DefaultValue: "0",
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(depositsCmd, cmdConfigs)
+}
@@ -0,0 +1,236 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export fills and transfers for accounting as CSV, XLSX, Parquet or OFX.",
+}
+
+// exportOptions holds the flags shared by the export subcommands.
+type exportOptions struct {
+	client       *intx.Client
+	portfolioIds []string
+	selected     map[string]bool
+	from         time.Time
+	to           time.Time
+	location     *time.Location
+	format       string
+	output       string
+	stateKey     string
+	lastExported time.Time
+}
+
+func loadExportOptions(cmd *cobra.Command, kind string) (*exportOptions, error) {
+	all, _ := cmd.Flags().GetBool(utils.AllFlag)
+
+	client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, !all)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize from environment: %w", err)
+	}
+
+	location, err := time.LoadLocation(utils.GetFlagStringValue(cmd, utils.TimezoneFlag))
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	opts := &exportOptions{
+		client:   client,
+		selected: map[string]bool{},
+		location: location,
+		output:   utils.GetFlagStringValue(cmd, utils.OutputFlag),
+		format:   strings.ToLower(utils.GetFlagStringValue(cmd, utils.OutputFormatFlag)),
+	}
+	if opts.format == "" {
+		opts.format = strings.TrimPrefix(strings.ToLower(filepath.Ext(opts.output)), ".")
+		if opts.format == "" {
+			opts.format = utils.ExportFormatCsv
+		}
+	}
+	if err := utils.ValidateExportFormat(opts.format); err != nil {
+		return nil, err
+	}
+
+	if opts.from, err = getExportTime(cmd, utils.FromFlag, location); err != nil {
+		return nil, err
+	}
+	if opts.to, err = getExportTime(cmd, utils.ToFlag, location); err != nil {
+		return nil, err
+	}
+
+	portfolios, err := utils.ListPortfolios(client)
+	if err != nil {
+		return nil, err
+	}
+	if !all {
+		if portfolios, err = utils.SelectPortfolios(portfolios, portfolioId); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range portfolios {
+		opts.portfolioIds = append(opts.portfolioIds, p.PortfolioId)
+		opts.selected[p.PortfolioId] = true
+		opts.selected[p.PortfolioUuid] = true
+		opts.selected[p.Name] = true
+	}
+	sort.Strings(opts.portfolioIds)
+
+	if incremental, _ := cmd.Flags().GetBool(utils.IncrementalFlag); incremental {
+		opts.stateKey = kind + ":" + strings.Join(opts.portfolioIds, ",")
+		if opts.lastExported, err = utils.LoadLastExported(opts.stateKey); err != nil {
+			return nil, fmt.Errorf("cannot read export state: %w", err)
+		}
+		if opts.lastExported.After(opts.from) {
+			opts.from = opts.lastExported
+		}
+	}
+
+	return opts, nil
+}
+
+func getExportTime(cmd *cobra.Command, flagName string, location *time.Location) (time.Time, error) {
+	value := utils.GetFlagStringValue(cmd, flagName)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return utils.ParseTimeIn(value, location)
+}
+
+// include reports whether a record at t belongs in the export. Incremental
+// exports skip records at the remembered timestamp, which were already written.
+func (o *exportOptions) include(t time.Time) bool {
+	if !o.lastExported.IsZero() && !t.After(o.lastExported) {
+		return false
+	}
+	return utils.InTimeRange(t, o.from, o.to)
+}
+
+func (o *exportOptions) timeFrom() string {
+	if o.from.IsZero() {
+		return ""
+	}
+	return o.from.UTC().Format(time.RFC3339)
+}
+
+func (o *exportOptions) timeTo() string {
+	if o.to.IsZero() {
+		return ""
+	}
+	return o.to.UTC().Format(time.RFC3339)
+}
+
+// write writes table and, for incremental exports, remembers latest as the
+// last exported timestamp once the file is complete.
+func (o *exportOptions) write(table *utils.ExportTable, latest time.Time) error {
+	out, err := utils.OpenOutput(o.output)
+	if err != nil {
+		return err
+	}
+
+	err = utils.WriteExport(out, o.format, table)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write export: %w", err)
+	}
+
+	if o.output != "" && o.output != "-" {
+		fmt.Fprintf(os.Stderr, "Wrote %d %s to %s\n", len(table.Rows), table.Sheet, o.output)
+	}
+
+	if o.stateKey != "" && !latest.IsZero() {
+		if err := utils.SaveLastExported(o.stateKey, latest); err != nil {
+			return fmt.Errorf("cannot save export state: %w", err)
+		}
+	}
+	return nil
+}
+
+func exportFlagConfigs() []utils.FlagConfig {
+	return []utils.FlagConfig{
+		{
+			FlagName:     utils.PortfolioIdFlag,
+			Shorthand:    "p",
+			Usage:        "Comma separated portfolio IDs or names. Uses environment variable if blank",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.AllFlag,
+			Shorthand:    "a",
+			Usage:        "Export all portfolios",
+			DefaultValue: false,
+			Required:     false,
+		},
+		{
+			FlagName:     utils.FromFlag,
+			Usage:        "Start of the export window (RFC3339 or YYYY-MM-DD in --timezone)",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.ToFlag,
+			Usage:        "End of the export window, exclusive (RFC3339 or YYYY-MM-DD in --timezone)",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.OutputFormatFlag,
+			Shorthand:    "f",
+			Usage:        "Export format: csv, xlsx, parquet or ofx. Derived from the output file extension if blank, else csv",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.OutputFlag,
+			Shorthand:    "o",
+			Usage:        "Output file. Writes to stdout if blank",
+			DefaultValue: "",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.TimezoneFlag,
+			Usage:        "IANA timezone for exported timestamps and date arguments, e.g. America/New_York",
+			DefaultValue: "UTC",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.IncrementalFlag,
+			Usage:        "Only export records newer than the last incremental export of the same portfolios",
+			DefaultValue: false,
+			Required:     false,
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+}
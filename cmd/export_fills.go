@@ -0,0 +1,103 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"sort"
+	"strings"
+	"time"
+)
+
+var exportFillsCmd = &cobra.Command{
+	Use:   "fills",
+	Short: "Export fills with notional, fees and fee currency of the selected portfolios.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := loadExportOptions(cmd, "fills")
+		if err != nil {
+			return err
+		}
+
+		fills, err := utils.ListAllFills(opts.client, strings.Join(opts.portfolioIds, ","), opts.timeFrom())
+		if err != nil {
+			return err
+		}
+
+		seen := map[string]bool{}
+		times := map[string]time.Time{}
+		var selected []intx.Fill
+		var latest time.Time
+		for _, f := range fills {
+			t, err := utils.ParseTime(f.EventTime)
+			if err != nil {
+				return fmt.Errorf("fill %s: %w", f.FillId, err)
+			}
+			if seen[f.FillId] || !opts.include(t) {
+				continue
+			}
+			seen[f.FillId] = true
+			times[f.FillId] = t
+			selected = append(selected, f)
+			if t.After(latest) {
+				latest = t
+			}
+		}
+		sort.SliceStable(selected, func(i, j int) bool {
+			return times[selected[i].FillId].Before(times[selected[j].FillId])
+		})
+
+		quoteCurrencies, err := getQuoteCurrencies(opts.client)
+		if err != nil {
+			return err
+		}
+
+		table, err := utils.BuildFillsExport(selected, quoteCurrencies, opts.location)
+		if err != nil {
+			return err
+		}
+		return opts.write(table, latest)
+	},
+}
+
+// getQuoteCurrencies maps instrument symbols to their quote asset, which is
+// the currency fill notional is paid in.
+func getQuoteCurrencies(client *intx.Client) (map[string]string, error) {
+	instruments, err := utils.ListInstruments(client)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := map[string]string{}
+	for _, i := range instruments {
+		quotes[i.Symbol] = i.QuoteAssetName
+	}
+	return quotes, nil
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command:    exportFillsCmd,
+			FlagConfig: exportFlagConfigs(),
+		},
+	}
+
+	utils.RegisterCommandConfigs(exportCmd, cmdConfigs)
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"sort"
+	"strings"
+	"time"
+)
+
+var exportTransfersCmd = &cobra.Command{
+	Use:   "transfers",
+	Short: "Export deposits, withdrawals, internal transfers and funding of the selected portfolios.",
+	Long: "Export deposits, withdrawals, internal transfers and funding of the selected portfolios.\n\n" +
+		"signed_amount is the flow into the selected portfolios, so transfers between two " +
+		"selected portfolios are exported with a signed amount of zero and left out of OFX statements.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := loadExportOptions(cmd, "transfers")
+		if err != nil {
+			return err
+		}
+
+		transfers, err := utils.ListAllTransfers(opts.client, utils.TransferFilter{
+			PortfolioIds: strings.Join(opts.portfolioIds, ","),
+			TimeFrom:     opts.timeFrom(),
+			TimeTo:       opts.timeTo(),
+		})
+		if err != nil {
+			return err
+		}
+
+		seen := map[string]bool{}
+		times := map[string]time.Time{}
+		var selected []utils.Transfer
+		var latest time.Time
+		for _, tr := range transfers {
+			t, err := utils.ParseTime(tr.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("transfer %s: %w", tr.TransferUuid, err)
+			}
+			if seen[tr.TransferUuid] || !opts.include(t) {
+				continue
+			}
+			seen[tr.TransferUuid] = true
+			times[tr.TransferUuid] = t
+			selected = append(selected, tr)
+			if t.After(latest) {
+				latest = t
+			}
+		}
+		sort.SliceStable(selected, func(i, j int) bool {
+			return times[selected[i].TransferUuid].Before(times[selected[j].TransferUuid])
+		})
+
+		table, err := utils.BuildTransfersExport(selected, opts.selected, opts.location)
+		if err != nil {
+			return err
+		}
+		return opts.write(table, latest)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command:    exportTransfersCmd,
+			FlagConfig: exportFlagConfigs(),
+		},
+	}
+
+	utils.RegisterCommandConfigs(exportCmd, cmdConfigs)
+}
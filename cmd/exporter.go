@@ -0,0 +1,152 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve balances, positions, margin and quotes as Prometheus metrics.",
+	Long: "Serve balances, positions, margin and quotes as Prometheus metrics.\n\n" +
+		"Portfolio state and quotes of the given instruments, and of instruments with open positions, are collected " +
+		"every --interval and served on /metrics together with API request, error and latency metrics.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := utils.InitClientAndPortfolioId(cmd, false)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		apiMetrics := utils.NewApiMetrics()
+		client.HttpClient.Transport = apiMetrics.Transport(client.HttpClient.Transport)
+
+		portfolios, err := utils.ListPortfolios(client)
+		if err != nil {
+			return err
+		}
+		if ids := utils.GetFlagStringValue(cmd, utils.PortfolioIdFlag); ids != "" {
+			if portfolios, err = utils.SelectPortfolios(portfolios, ids); err != nil {
+				return err
+			}
+		}
+
+		var instruments []string
+		for _, id := range strings.Split(utils.GetFlagStringValue(cmd, utils.InstrumentIdFlag), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				instruments = append(instruments, id)
+			}
+		}
+
+		interval, err := utils.GetFlagDurationValue(cmd, utils.IntervalFlag)
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--%s must be positive", utils.IntervalFlag)
+		}
+		concurrency, _ := cmd.Flags().GetInt(utils.ConcurrencyFlag)
+
+		var mu sync.Mutex
+		var current *utils.MetricSet
+		collect := func() {
+			metrics, errs := utils.CollectExchangeMetrics(client, portfolios, instruments, concurrency)
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+			}
+			mu.Lock()
+			current = metrics
+			mu.Unlock()
+		}
+
+		collect()
+		go func() {
+			for {
+				time.Sleep(interval)
+				collect()
+			}
+		}()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			metrics := current
+			mu.Unlock()
+
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			if err := utils.WriteMetrics(w, metrics.Families(), apiMetrics.Families()); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: cannot write metrics: %v\n", err)
+			}
+		})
+
+		listen := utils.GetFlagStringValue(cmd, utils.ListenFlag)
+		fmt.Fprintf(os.Stderr, "Serving metrics of %d portfolios on %s/metrics\n", len(portfolios), listen)
+		return http.ListenAndServe(listen, mux)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: exporterCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.ListenFlag,
+					Usage:        "Address to serve metrics on",
+					DefaultValue: ":9400",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Comma separated portfolio IDs, UUIDs or names. All portfolios if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.InstrumentIdFlag,
+					Shorthand:    "i",
+					Usage:        "Comma separated instruments to export quotes and funding rates for, in addition to those with positions",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.IntervalFlag,
+					Usage:        "Collection interval, e.g. 30s",
+					DefaultValue: "30s",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ConcurrencyFlag,
+					Shorthand:    "c",
+					Usage:        "Maximum number of portfolios fetched at once",
+					DefaultValue: utils.DefaultConcurrency,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"math"
+)
+
+var flipPositionCmd = &cobra.Command{
+	Use:   "flip-position",
+	Short: "Reverse a position with one order of twice its size.",
+	Long: "Reverse a position with one order of twice its size, so that a long becomes a short of the same size and " +
+		"the other way around.\n\n" +
+		"The order takes the same type, price and wait options as close-position. It is not close-only, so a partial " +
+		"fill can leave a smaller position on either side.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		position, err := getInstrumentPosition(client, portfolioId, utils.GetFlagStringValue(cmd, utils.InstrumentIdFlag))
+		if err != nil {
+			return err
+		}
+
+		request, err := positionOrderRequest(cmd, client, portfolioId, position, 2*math.Abs(utils.PositionSize(position)))
+		if err != nil {
+			return err
+		}
+		return submitPositionOrder(cmd, client, request, false)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command:    flipPositionCmd,
+			FlagConfig: positionOrderFlagConfigs(),
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -0,0 +1,198 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultFundingHistoryWindow = 7 * 24 * time.Hour
+
+var fundingHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Compute funding paid or received per position over a period.",
+	Long: "Compute funding paid or received per position over a period.\n\n" +
+		"Position sizes at each funding event are reconstructed by unwinding fills from the current " +
+		"position. The estimate is shown next to the funding transfers actually booked.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		from, err := utils.GetFlagTimeValue(cmd, utils.FromFlag)
+		if err != nil {
+			return err
+		}
+		if from.IsZero() {
+			from = time.Now().Add(-defaultFundingHistoryWindow)
+		}
+		to, err := utils.GetFlagTimeValue(cmd, utils.ToFlag)
+		if err != nil {
+			return err
+		}
+
+		fills, err := utils.ListAllFills(client, portfolioId, from.UTC().Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+		fillsBySymbol := utils.FillsBySymbol(fills)
+
+		positions, err := utils.GetPositions(client, portfolioId)
+		if err != nil {
+			return err
+		}
+		sizes := map[string]float64{}
+		for _, p := range positions {
+			sizes[p.Symbol] = utils.ParseAmountOrZero(p.NetSize)
+		}
+
+		var instruments []string
+		if instrumentId := utils.GetFlagStringValue(cmd, utils.InstrumentIdFlag); instrumentId != "" {
+			instruments = []string{instrumentId}
+		} else {
+			all, err := utils.ListInstruments(client)
+			if err != nil {
+				return err
+			}
+			perps := map[string]bool{}
+			for _, i := range all {
+				if strings.EqualFold(i.Type, utils.InstrumentTypePerp) {
+					perps[i.Symbol] = true
+				}
+			}
+
+			// Spot positions and fills have no funding.
+			seen := map[string]bool{}
+			for symbol, size := range sizes {
+				if size != 0 && perps[symbol] {
+					seen[symbol] = true
+				}
+			}
+			for symbol := range fillsBySymbol {
+				if perps[symbol] {
+					seen[symbol] = true
+				}
+			}
+			for symbol := range seen {
+				instruments = append(instruments, symbol)
+			}
+			sort.Strings(instruments)
+		}
+
+		actual, err := getFunding(client, portfolioId, from, to)
+		if err != nil {
+			return err
+		}
+
+		var estimates []*utils.FundingEstimate
+		total := &utils.FundingEstimate{Instrument: "TOTAL"}
+		for _, instrument := range instruments {
+			rates, err := utils.ListFundingRates(client, instrument, from, 0)
+			if err != nil {
+				return err
+			}
+
+			estimate, err := utils.EstimateFunding(instrument, sizes[instrument], fillsBySymbol[instrument], rates, from, to)
+			if err != nil {
+				return err
+			}
+			estimate.Actual = actual[instrument]
+			estimates = append(estimates, estimate)
+
+			total.Events += estimate.Events
+			total.Estimated += estimate.Estimated
+			total.Actual += estimate.Actual
+		}
+
+		report := &utils.Report{
+			Value:   estimates,
+			Headers: []string{"INSTRUMENT", "EVENTS", "AVG_SIZE", "AVG_RATE", "ESTIMATED", "ACTUAL"},
+		}
+		for _, e := range estimates {
+			report.AddRow(
+				e.Instrument,
+				strconv.Itoa(e.Events),
+				utils.FormatRounded(e.AvgSize, 8),
+				utils.FormatRounded(e.AvgRate, 10),
+				utils.FormatRounded(e.Estimated, 8),
+				utils.FormatRounded(e.Actual, 8),
+			)
+		}
+		report.AddRow(total.Instrument, strconv.Itoa(total.Events), "", "",
+			utils.FormatRounded(total.Estimated, 8), utils.FormatRounded(total.Actual, 8))
+
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: fundingHistoryCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.InstrumentIdFlag,
+					Shorthand:    "i",
+					Usage:        "Instrument to report. Defaults to all perpetuals traded or held",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FromFlag,
+					Usage:        "Start of the period (RFC3339 or YYYY-MM-DD). Defaults to 7 days ago",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ToFlag,
+					Usage:        "End of the period, exclusive (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(fundingCmd, cmdConfigs)
+}
@@ -0,0 +1,120 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+type projectedFunding struct {
+	Instrument    string  `json:"instrument"`
+	NetSize       float64 `json:"netSize"`
+	MarkPrice     float64 `json:"markPrice"`
+	PredictedRate float64 `json:"predictedRate"`
+	Payment       float64 `json:"payment"`
+}
+
+var fundingProjectedCmd = &cobra.Command{
+	Use:   "projected",
+	Short: "Project the next funding payment of open positions from the predicted rate.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		positions, err := utils.GetPositions(client, portfolioId)
+		if err != nil {
+			return err
+		}
+
+		var projections []projectedFunding
+		total := 0.0
+		for _, p := range positions {
+			size := utils.ParseAmountOrZero(p.NetSize)
+			if size == 0 {
+				continue
+			}
+
+			quote, err := utils.GetQuote(client, p.Symbol)
+			if err != nil {
+				return err
+			}
+
+			projection := projectedFunding{
+				Instrument:    p.Symbol,
+				NetSize:       size,
+				MarkPrice:     utils.ParseAmountOrZero(quote.MarkPrice),
+				PredictedRate: utils.ParseAmountOrZero(quote.PredictedFunding),
+			}
+			projection.Payment = utils.FundingPayment(size, projection.PredictedRate, projection.MarkPrice)
+			total += projection.Payment
+			projections = append(projections, projection)
+		}
+
+		report := &utils.Report{
+			Value:   projections,
+			Headers: []string{"INSTRUMENT", "NET_SIZE", "MARK", "PREDICTED_RATE", "PAYMENT"},
+		}
+		for _, p := range projections {
+			report.AddRow(
+				p.Instrument,
+				utils.FormatRounded(p.NetSize, 8),
+				utils.FormatRounded(p.MarkPrice, 8),
+				utils.FormatRounded(p.PredictedRate, 10),
+				utils.FormatRounded(p.Payment, 8),
+			)
+		}
+		report.AddRow("TOTAL", "", "", "", utils.FormatRounded(total, 8))
+
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: fundingProjectedCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(fundingCmd, cmdConfigs)
+}
@@ -0,0 +1,132 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultFundingStatsWindow = 30 * 24 * time.Hour
+
+var fundingStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize funding rate statistics across perpetual instruments.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := utils.InitClientAndPortfolioId(cmd, false)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		from, err := utils.GetFlagTimeValue(cmd, utils.FromFlag)
+		if err != nil {
+			return err
+		}
+		if from.IsZero() {
+			from = time.Now().Add(-defaultFundingStatsWindow)
+		}
+
+		instruments, err := utils.ListInstruments(client)
+		if err != nil {
+			return err
+		}
+		sort.Slice(instruments, func(i, j int) bool { return instruments[i].Symbol < instruments[j].Symbol })
+
+		only := utils.GetFlagStringValue(cmd, utils.InstrumentIdFlag)
+
+		var stats []*utils.FundingStats
+		for _, instrument := range instruments {
+			if !strings.EqualFold(instrument.Type, utils.InstrumentTypePerp) {
+				continue
+			}
+			if only != "" && !strings.EqualFold(only, instrument.Symbol) && only != instrument.InstrumentId {
+				continue
+			}
+
+			rates, err := utils.ListFundingRates(client, instrument.Symbol, from, 0)
+			if err != nil {
+				return err
+			}
+			predicted := utils.ParseAmountOrZero(instrument.Quote.PredictedFunding)
+			stats = append(stats, utils.ComputeFundingStats(instrument.Symbol, rates, predicted))
+		}
+
+		report := &utils.Report{
+			Value:   stats,
+			Headers: []string{"INSTRUMENT", "COUNT", "MEAN", "MEDIAN", "MIN", "MAX", "STDDEV", "ANNUALIZED", "PREDICTED", "ANNUALIZED_PREDICTED"},
+		}
+		for _, s := range stats {
+			report.AddRow(
+				s.Instrument,
+				strconv.Itoa(s.Count),
+				utils.FormatRounded(s.Mean, 10),
+				utils.FormatRounded(s.Median, 10),
+				utils.FormatRounded(s.Min, 10),
+				utils.FormatRounded(s.Max, 10),
+				utils.FormatRounded(s.StdDev, 10),
+				utils.FormatRounded(s.AnnualizedMean, 6),
+				utils.FormatRounded(s.PredictedRate, 10),
+				utils.FormatRounded(s.AnnualizedPredicted, 6),
+			)
+		}
+
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: fundingStatsCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.InstrumentIdFlag,
+					Shorthand:    "i",
+					Usage:        "Only report this instrument. Defaults to all perpetuals",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FromFlag,
+					Usage:        "Start of the sample (RFC3339 or YYYY-MM-DD). Defaults to 30 days ago",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(fundingCmd, cmdConfigs)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/coinbase-samples/intx-cli/utils"
 	"github.com/coinbase-samples/intx-sdk-go"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 var getTransferCmd = &cobra.Command{
@@ -32,11 +33,16 @@ var getTransferCmd = &cobra.Command{
 			return fmt.Errorf("cannot initialize from environment: %w", err)
 		}
 
+		transferUuid := utils.GetFlagStringValue(cmd, utils.TransferIdFlag)
+		if wait, _ := cmd.Flags().GetBool(utils.WaitFlag); wait {
+			return waitForTransfer(cmd, client, transferUuid)
+		}
+
 		ctx, cancel := utils.GetContextWithTimeout()
 		defer cancel()
 
 		request := &intx.GetTransferRequest{
-			TransferUuid: utils.GetFlagStringValue(cmd, utils.TransferIdFlag),
+			TransferUuid: transferUuid,
 		}
 
 		response, err := client.GetTransfer(ctx, request)
@@ -48,11 +54,42 @@ var getTransferCmd = &cobra.Command{
 	},
 }
 
+// waitForTransfer polls the transfer until it is final, printing status
+// changes to stderr and the final transfer to stdout.
+func waitForTransfer(cmd *cobra.Command, client *intx.Client, transferUuid string) error {
+	opts, err := getTransferWatchOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	tracker := utils.NewTransferTracker()
+	tracker.Track(transferUuid)
+	var last *utils.TransferDetails
+	err = watchTransfers(opts, tracker, os.Stderr, func() ([]*utils.TransferDetails, error) {
+		d, err := utils.GetTransferDetails(client, transferUuid)
+		if err != nil {
+			return nil, err
+		}
+		last = d
+		return []*utils.TransferDetails{d}, nil
+	})
+
+	if last != nil {
+		if printErr := utils.PrintJsonResponse(cmd, last); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+	if err != nil {
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
 func init() {
 	cmdConfigs := []utils.CommandConfig{
 		{
 			Command: getTransferCmd,
-			FlagConfig: []utils.FlagConfig{
+			FlagConfig: append([]utils.FlagConfig{
 				{
 					FlagName:     utils.TransferIdFlag,
 					Shorthand:    "i",
@@ -60,6 +97,13 @@ func init() {
 					DefaultValue: "",
 					Required:     true,
 				},
+				{
+					FlagName:     utils.WaitFlag,
+					Shorthand:    "w",
+					Usage:        "Poll until the transfer is processed or failed. Exit status is 0 when processed, 2 when failed and 3 on timeout",
+					DefaultValue: false,
+					Required:     false,
+				},
 				{
 					FlagName:     utils.FormatFlag,
 					Shorthand:    "z",
@@ -67,7 +111,7 @@ func init() {
 					DefaultValue: false,
 					Required:     false,
 				},
-			},
+			}, transferWatchFlagConfigs()...),
 		},
 	}
 
@@ -0,0 +1,109 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"strings"
+)
+
+// longRunningCommands run until interrupted and cannot be run by the
+// scheduler or a playbook.
+var longRunningCommands = []string{"scheduler", "exporter", "alerts run", "tui", "transfers watch", "deposits watch"}
+
+// checkInProcessCommand resolves a command line, rejecting long running
+// commands and the excluded command paths.
+func checkInProcessCommand(args []string, excluded ...string) (*cobra.Command, error) {
+	c, _, err := rootCmd.Find(args)
+	if err != nil {
+		return nil, err
+	}
+	if c == rootCmd {
+		return nil, fmt.Errorf("unknown command %s", args[0])
+	}
+
+	path := strings.TrimPrefix(c.CommandPath(), rootCmd.Name()+" ")
+	for _, u := range longRunningCommands {
+		if path == u {
+			return nil, fmt.Errorf("%s runs until interrupted and cannot be run this way", c.CommandPath())
+		}
+	}
+	for _, u := range excluded {
+		if path == u {
+			return nil, fmt.Errorf("%s cannot be run this way", c.CommandPath())
+		}
+	}
+	return c, nil
+}
+
+// executeInProcess runs an intxctl command line in this process with stdin
+// closed and stdout and stderr redirected. API calls are cancelled when ctx
+// is done. Commands share process state, so only one may run at a time.
+func executeInProcess(ctx context.Context, args []string, stdout, stderr *os.File) (err error) {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
+	}
+	defer devNull.Close()
+
+	stdin, savedStdout, savedStderr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = devNull, stdout, stderr
+	previous := utils.SetBaseContext(ctx)
+	defer func() {
+		utils.SetBaseContext(previous)
+		os.Stdin, os.Stdout, os.Stderr = stdin, savedStdout, savedStderr
+		if r := recover(); r != nil {
+			err = fmt.Errorf("command panicked: %v", r)
+		}
+	}()
+
+	c, _, err := rootCmd.Find(args)
+	if err != nil {
+		return err
+	}
+	resetFlags(c)
+	utils.TakeAuditFailure()
+	rootCmd.SetArgs(args)
+	if _, err = rootCmd.ExecuteC(); err != nil {
+		utils.TakeAuditFailure()
+		return err
+	}
+	return utils.TakeAuditFailure()
+}
+
+// resetFlags restores the defaults of the flags of a command and its parents,
+// which keep the values of the previous run otherwise. Slice flags are emptied
+// instead, since Set appends and their defaults are all empty.
+func resetFlags(c *cobra.Command) {
+	for ; c != nil; c = c.Parent() {
+		for _, flags := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if slice, ok := f.Value.(pflag.SliceValue); ok {
+					slice.Replace(nil)
+				} else {
+					f.Value.Set(f.DefValue)
+				}
+				f.Changed = false
+			})
+		}
+	}
+}
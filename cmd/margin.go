@@ -0,0 +1,192 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+type marginResult struct {
+	Exchange *utils.MarginState `json:"exchange"`
+	Current  *utils.MarginState `json:"current"`
+	WhatIf   *utils.MarginState `json:"whatIf,omitempty"`
+	Model    *utils.MarginModel `json:"model"`
+}
+
+var marginCmd = &cobra.Command{
+	Use:   "margin",
+	Short: "Calculate margin requirements, margin ratio and liquidation prices, with optional what-if scenarios.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		changes, err := utils.ParsePositionChanges(utils.GetFlagStringValue(cmd, utils.AddFlag))
+		if err != nil {
+			return err
+		}
+
+		var shock float64
+		if value := utils.GetFlagStringValue(cmd, utils.ShockFlag); value != "" {
+			if shock, err = utils.ParsePercent(value); err != nil {
+				return err
+			}
+			if shock <= -1 {
+				return fmt.Errorf("invalid shock %s: prices cannot fall by 100%% or more", value)
+			}
+		}
+
+		state, err := utils.FetchPortfolioState(client, &intx.Portfolio{PortfolioId: portfolioId})
+		if err != nil {
+			return err
+		}
+
+		instruments, err := utils.ListInstruments(client)
+		if err != nil {
+			return err
+		}
+
+		summary := state.Summary
+		collateral := utils.ParseAmountOrZero(summary.Collateral)
+		positions := utils.MarginPositions(state.Positions)
+
+		model := utils.NewMarginModel(summary, instruments)
+		model.Calibrate(positions, summary.PortfolioInitialMarginNotional)
+
+		result := &marginResult{
+			Exchange: utils.ReportedMarginState(summary),
+			Current:  model.Compute(collateral, positions),
+			Model:    model,
+		}
+
+		if len(changes) > 0 || shock != 0 {
+			marks := map[string]float64{}
+			for _, i := range instruments {
+				marks[i.Symbol] = utils.ParseAmountOrZero(i.Quote.MarkPrice)
+			}
+
+			whatIfCollateral, whatIfPositions, err := utils.ApplyWhatIf(collateral, positions, changes, shock, marks)
+			if err != nil {
+				return fmt.Errorf("cannot apply what-if scenario: %w", err)
+			}
+			result.WhatIf = model.Compute(whatIfCollateral, whatIfPositions)
+		}
+
+		summaryReport := &utils.Report{
+			Headers: []string{"SCENARIO", "COLLATERAL", "INITIAL_MARGIN", "MAINT_MARGIN", "MARGIN_RATIO", "MARGIN_USAGE", "EXCESS_COLLATERAL", "IN_LIQUIDATION"},
+		}
+		positionReport := &utils.Report{
+			Headers: []string{"SCENARIO", "INSTRUMENT", "SIZE", "MARK_PRICE", "NOTIONAL", "IMF", "INITIAL_MARGIN", "MAINT_MARGIN", "LIQUIDATION_PRICE"},
+		}
+
+		scenarios := []struct {
+			name  string
+			state *utils.MarginState
+		}{
+			{"exchange", result.Exchange},
+			{"current", result.Current},
+			{"what-if", result.WhatIf},
+		}
+		for _, s := range scenarios {
+			if s.state == nil {
+				continue
+			}
+			summaryReport.AddRow(
+				s.name,
+				utils.FormatRounded(s.state.Collateral, 2),
+				utils.FormatRounded(s.state.InitialMargin, 2),
+				utils.FormatRounded(s.state.MaintenanceMargin, 2),
+				utils.FormatRounded(s.state.MarginRatio*100, 2)+"%",
+				utils.FormatRounded(s.state.MarginUsage*100, 2)+"%",
+				utils.FormatRounded(s.state.ExcessCollateral, 2),
+				strconv.FormatBool(s.state.InLiquidation),
+			)
+
+			for _, p := range s.state.Positions {
+				liquidationPrice := "none"
+				if p.LiquidationPrice > 0 {
+					liquidationPrice = utils.FormatRounded(p.LiquidationPrice, 2)
+				}
+				positionReport.AddRow(
+					s.name,
+					p.Instrument,
+					utils.FormatRounded(p.Size, 8),
+					utils.FormatRounded(p.MarkPrice, 2),
+					utils.FormatRounded(p.Notional, 2),
+					utils.FormatRounded(p.Imf, 4),
+					utils.FormatRounded(p.InitialMargin, 2),
+					utils.FormatRounded(p.MaintenanceMargin, 2),
+					liquidationPrice,
+				)
+			}
+		}
+
+		return utils.PrintReports(cmd, result, summaryReport, positionReport)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: marginCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AddFlag,
+					Shorthand:    "a",
+					Usage:        "What-if position changes as INSTRUMENT:SIZE, comma separated, e.g. BTC-PERP:+2",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ShockFlag,
+					Shorthand:    "s",
+					Usage:        "What-if move of all mark prices, e.g. -10%",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
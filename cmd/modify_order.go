@@ -24,8 +24,9 @@ import (
 )
 
 var modifyOrderCmd = &cobra.Command{
-	Use:   "modify-order",
-	Short: "Submit an order modification request.",
+	Use:         "modify-order",
+	Short:       "Submit an order modification request.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -45,6 +46,7 @@ var modifyOrderCmd = &cobra.Command{
 		}
 
 		response, err := client.ModifyOrder(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot modify order: %w", err)
 		}
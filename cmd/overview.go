@@ -0,0 +1,147 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+)
+
+var overviewCmd = &cobra.Command{
+	Use:   "overview",
+	Short: "Show a consolidated view of collateral, margin and exposure across all portfolios.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := utils.InitClientAndPortfolioId(cmd, false)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		portfolios, err := utils.ListPortfolios(client)
+		if err != nil {
+			return err
+		}
+
+		concurrency, _ := cmd.Flags().GetInt(utils.ConcurrencyFlag)
+		states, errs := utils.FetchPortfolioStates(client, portfolios, concurrency)
+
+		var fetched []*utils.PortfolioState
+		var failed []*utils.PortfolioOverview
+		for i, state := range states {
+			if errs[i] != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: %v\n", errs[i])
+				failed = append(failed, &utils.PortfolioOverview{
+					PortfolioId: portfolios[i].PortfolioId,
+					Name:        portfolios[i].Name,
+					Error:       errs[i].Error(),
+				})
+				continue
+			}
+			fetched = append(fetched, state)
+		}
+
+		overview := utils.ComputeOverview(fetched)
+		overview.Portfolios = append(overview.Portfolios, failed...)
+
+		portfolioReport := &utils.Report{
+			Headers: []string{"PORTFOLIO", "NAME", "COLLATERAL", "UNREALIZED_PNL", "INITIAL_MARGIN", "MAINT_MARGIN", "MARGIN_USAGE", "POSITION_NOTIONAL", "OPEN_ORDERS", "STATUS"},
+		}
+		for _, o := range append(overview.Portfolios, overview.Total) {
+			status := "ok"
+			if o.Error != "" {
+				status = "error"
+			} else if o == overview.Total && len(failed) > 0 {
+				status = "incomplete"
+			} else if o.InLiquidation {
+				status = "LIQUIDATION"
+			}
+			portfolioReport.AddRow(
+				o.PortfolioId,
+				o.Name,
+				utils.FormatRounded(o.Collateral, 2),
+				utils.FormatRounded(o.UnrealizedPnl, 2),
+				utils.FormatRounded(o.InitialMargin, 2),
+				utils.FormatRounded(o.MaintenanceMargin, 2),
+				utils.FormatRounded(o.MarginUsage*100, 2)+"%",
+				utils.FormatRounded(o.PositionNotional, 2),
+				strconv.Itoa(o.OpenOrders),
+				status,
+			)
+		}
+
+		exposureReport := &utils.Report{
+			Headers: []string{"INSTRUMENT", "NET_SIZE", "NET_NOTIONAL", "GROSS_NOTIONAL", "PORTFOLIOS"},
+		}
+		for _, e := range overview.Exposures {
+			exposureReport.AddRow(
+				e.Instrument,
+				utils.FormatRounded(e.NetSize, 8),
+				utils.FormatRounded(e.NetNotional, 2),
+				utils.FormatRounded(e.GrossNotional, 2),
+				strconv.Itoa(e.Portfolios),
+			)
+		}
+		exposureReport.AddRow("NET_DELTA", "", utils.FormatRounded(overview.NetDelta, 2), "", "")
+
+		if err := utils.PrintReports(cmd, overview, portfolioReport, exposureReport); err != nil {
+			return err
+		}
+
+		if len(failed) > 0 {
+			cmd.SilenceUsage = true
+			return &utils.ExitError{
+				Code: utils.ExitCodeFailed,
+				Err:  fmt.Errorf("%d of %d portfolios could not be loaded: totals are incomplete", len(failed), len(portfolios)),
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: overviewCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.ConcurrencyFlag,
+					Shorthand:    "c",
+					Usage:        "Maximum number of portfolios fetched at once",
+					DefaultValue: utils.DefaultConcurrency,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
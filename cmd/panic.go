@@ -0,0 +1,448 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	panicConfirmWord = "PANIC"
+
+	panicStatusOk         = "OK"
+	panicStatusIncomplete = "INCOMPLETE"
+	panicStatusUnverified = "UNVERIFIED"
+)
+
+type panicOptions struct {
+	closePositions bool
+	method         string
+	slippage       float64
+	timeout        time.Duration
+	interval       time.Duration
+	instruments    map[string]*intx.Instrument
+}
+
+type panicClose struct {
+	Instrument string `json:"instrument"`
+	Side       string `json:"side"`
+	Size       string `json:"size"`
+	Type       string `json:"type"`
+	Price      string `json:"price,omitempty"`
+	OrderId    string `json:"orderId,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type panicResult struct {
+	PortfolioId        string        `json:"portfolioId"`
+	Name               string        `json:"name"`
+	CancelledOrders    int           `json:"cancelledOrders"`
+	Closes             []*panicClose `json:"closes"`
+	RemainingOrders    int           `json:"remainingOrders"`
+	RemainingPositions []string      `json:"remainingPositions"`
+	Status             string        `json:"status"`
+	Errors             []string      `json:"errors,omitempty"`
+}
+
+func (r *panicResult) addError(err error) {
+	r.Errors = append(r.Errors, err.Error())
+}
+
+var panicCmd = &cobra.Command{
+	Use:   "panic",
+	Short: "Cancel all open orders and optionally close all positions.",
+	Long: "Cancel all open orders of the selected portfolios and, with --close-positions, close every position " +
+		"with close-only IOC orders, then check until nothing remains or --timeout passes.\n\n" +
+		"Unless --yes is given, the command shows what it will do and asks for " + panicConfirmWord + " to be typed. " +
+		"It exits with status 2 when orders or positions remain.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool(utils.AllFlag)
+
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, !all)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		environment, err := utils.ResolveEnvironment(cmd)
+		if err != nil {
+			return err
+		}
+
+		opts := &panicOptions{
+			method: strings.ToLower(utils.GetFlagStringValue(cmd, utils.MethodFlag)),
+		}
+		opts.closePositions, _ = cmd.Flags().GetBool(utils.ClosePositionsFlag)
+		if opts.method != utils.CloseMethodMarket && opts.method != utils.CloseMethodLimit {
+			return fmt.Errorf("invalid --%s %s: must be %s or %s", utils.MethodFlag, opts.method, utils.CloseMethodMarket, utils.CloseMethodLimit)
+		}
+		if opts.slippage, err = utils.ParsePercent(utils.GetFlagStringValue(cmd, utils.SlippageFlag)); err != nil {
+			return err
+		}
+		if opts.slippage < 0 || opts.slippage >= 1 {
+			return fmt.Errorf("invalid --%s: must be between 0%% and 100%%", utils.SlippageFlag)
+		}
+		if opts.timeout, err = utils.GetFlagDurationValue(cmd, utils.TimeoutFlag); err != nil {
+			return err
+		}
+		if opts.interval, err = utils.GetFlagDurationValue(cmd, utils.IntervalFlag); err != nil {
+			return err
+		}
+		if opts.interval <= 0 {
+			return fmt.Errorf("invalid --%s: must be positive", utils.IntervalFlag)
+		}
+
+		portfolios, err := utils.ListPortfolios(client)
+		if err != nil {
+			return err
+		}
+		if !all {
+			if portfolios, err = utils.SelectPortfolios(portfolios, portfolioId); err != nil {
+				return err
+			}
+		}
+		if len(portfolios) == 0 {
+			return errors.New("no portfolios selected")
+		}
+
+		concurrency, _ := cmd.Flags().GetInt(utils.ConcurrencyFlag)
+		if yes, _ := cmd.Flags().GetBool(utils.YesFlag); !yes {
+			states, errs := utils.FetchPortfolioStates(client, portfolios, concurrency)
+			printPanicPreview(portfolios, states, errs)
+
+			action := "Cancel all open orders"
+			if opts.closePositions {
+				action += fmt.Sprintf(" and close all positions with %s orders", opts.method)
+			}
+			prompt := fmt.Sprintf("%s in %d portfolios on %s?", action, len(portfolios), environment.Name)
+			if !utils.ConfirmTyped(prompt, panicConfirmWord) {
+				return errors.New("panic cancelled")
+			}
+		}
+
+		if opts.closePositions && opts.method == utils.CloseMethodLimit {
+			if instruments, err := utils.ListInstruments(client); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: limit prices are not rounded to the price increment: %v\n", err)
+			} else {
+				opts.instruments = utils.IndexInstruments(instruments)
+			}
+		}
+
+		results := make([]*panicResult, len(portfolios))
+		sem := make(chan struct{}, max(concurrency, 1))
+		var wg sync.WaitGroup
+		for i, p := range portfolios {
+			wg.Add(1)
+			go func(i int, p *intx.Portfolio) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[i] = flattenPortfolio(cmd, client, p, opts)
+			}(i, p)
+		}
+		wg.Wait()
+
+		portfolioReport := &utils.Report{
+			Headers: []string{"PORTFOLIO", "NAME", "CANCELLED", "CLOSE_ORDERS", "REMAINING_ORDERS", "REMAINING_POSITIONS", "STATUS", "ERRORS"},
+		}
+		closeReport := &utils.Report{
+			Headers: []string{"PORTFOLIO", "INSTRUMENT", "SIDE", "SIZE", "TYPE", "PRICE", "ORDER_ID", "STATUS", "ERROR"},
+		}
+		incomplete := 0
+		for _, r := range results {
+			if r.Status != panicStatusOk {
+				incomplete++
+			}
+			portfolioReport.AddRow(
+				r.PortfolioId,
+				r.Name,
+				fmt.Sprint(r.CancelledOrders),
+				fmt.Sprint(len(r.Closes)),
+				fmt.Sprint(r.RemainingOrders),
+				strings.Join(r.RemainingPositions, ", "),
+				r.Status,
+				strings.Join(r.Errors, "; "),
+			)
+			for _, c := range r.Closes {
+				closeReport.AddRow(r.PortfolioId, c.Instrument, c.Side, c.Size, c.Type, c.Price, c.OrderId, c.Status, c.Error)
+			}
+		}
+
+		reports := []*utils.Report{portfolioReport}
+		if opts.closePositions {
+			reports = append(reports, closeReport)
+		}
+		if err := utils.PrintReports(cmd, results, reports...); err != nil {
+			return err
+		}
+
+		if incomplete > 0 {
+			return &utils.ExitError{
+				Code: utils.ExitCodeFailed,
+				Err:  fmt.Errorf("%d of %d portfolios still have open orders or positions, or could not be checked", incomplete, len(results)),
+			}
+		}
+		return nil
+	},
+}
+
+func printPanicPreview(portfolios []*intx.Portfolio, states []*utils.PortfolioState, errs []error) {
+	for i, p := range portfolios {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "  %s (%s): cannot load state: %v\n", p.Name, p.PortfolioId, errs[i])
+			continue
+		}
+		positions := utils.OpenPositions(states[i].Positions)
+		fmt.Fprintf(os.Stderr, "  %s (%s): %d open orders, %d positions", p.Name, p.PortfolioId, len(states[i].OpenOrders), len(positions))
+		if len(positions) > 0 {
+			fmt.Fprintf(os.Stderr, ": %s", strings.Join(formatPanicPositions(positions), ", "))
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func formatPanicPositions(positions []intx.Position) []string {
+	formatted := []string{}
+	for _, p := range positions {
+		formatted = append(formatted, fmt.Sprintf("%s %s", utils.PositionInstrument(p), p.NetSize))
+	}
+	return formatted
+}
+
+// flattenPortfolio cancels the open orders of a portfolio, closes its
+// positions when asked to, and checks the outcome. Orders and positions that
+// remain are cancelled and closed again on every check until the timeout, as
+// IOC closing orders may fill only partly. Failures are recorded in the
+// result rather than stopping, so that as much risk as possible is removed.
+// Checking stops early when the base context of API calls is done.
+func flattenPortfolio(cmd *cobra.Command, client *intx.Client, portfolio *intx.Portfolio, opts *panicOptions) *panicResult {
+	result := &panicResult{
+		PortfolioId:        portfolio.PortfolioId,
+		Name:               portfolio.Name,
+		Closes:             []*panicClose{},
+		RemainingPositions: []string{},
+	}
+
+	cancelPanicOrders(cmd, client, portfolio.PortfolioId, result)
+	if opts.closePositions {
+		if positions, err := utils.GetPositions(client, portfolio.PortfolioId); err != nil {
+			result.addError(err)
+		} else {
+			closePanicPositions(cmd, client, portfolio.PortfolioId, utils.OpenPositions(positions), opts, result)
+		}
+	}
+
+	ctx := utils.BaseContext()
+	deadline := time.Now().Add(opts.timeout)
+	for {
+		if err := utils.Sleep(ctx, opts.interval); err != nil {
+			result.Status = panicStatusUnverified
+			result.addError(fmt.Errorf("stopped checking: %w", err))
+			return result
+		}
+
+		orders, positions, err := checkPanicPortfolio(client, portfolio.PortfolioId)
+		if err == nil {
+			result.RemainingOrders = len(orders)
+			result.RemainingPositions = formatPanicPositions(positions)
+			if len(orders) == 0 && (!opts.closePositions || len(positions) == 0) {
+				result.Status = panicStatusOk
+				return result
+			}
+		}
+
+		if !time.Now().Add(opts.interval).Before(deadline) || ctx.Err() != nil {
+			result.Status = panicStatusIncomplete
+			if err != nil {
+				result.Status = panicStatusUnverified
+				result.addError(err)
+			}
+			return result
+		}
+
+		if err == nil {
+			if len(orders) > 0 {
+				cancelPanicOrders(cmd, client, portfolio.PortfolioId, result)
+			}
+			if opts.closePositions {
+				closePanicPositions(cmd, client, portfolio.PortfolioId, positions, opts, result)
+			}
+		}
+	}
+}
+
+func cancelPanicOrders(cmd *cobra.Command, client *intx.Client, portfolioId string, result *panicResult) {
+	ctx, cancel := utils.GetContextWithTimeout()
+	request := &intx.CancelOrdersRequest{PortfolioId: portfolioId}
+	response, err := client.CancelOrders(ctx, request)
+	cancel()
+	utils.RecordAudit(cmd, request, response, err)
+	if err != nil {
+		result.addError(fmt.Errorf("cannot cancel orders: %w", err))
+		return
+	}
+	result.CancelledOrders += len(response.Order)
+}
+
+func closePanicPositions(cmd *cobra.Command, client *intx.Client, portfolioId string, positions []intx.Position, opts *panicOptions, result *panicResult) {
+	for _, p := range positions {
+		result.Closes = append(result.Closes, closePanicPosition(cmd, client, portfolioId, p, opts))
+	}
+}
+
+func closePanicPosition(cmd *cobra.Command, client *intx.Client, portfolioId string, position intx.Position, opts *panicOptions) *panicClose {
+	instrument := utils.PositionInstrument(position)
+	result := &panicClose{Instrument: instrument}
+
+	var quote *intx.Quote
+	if opts.method == utils.CloseMethodLimit {
+		var err error
+		if quote, err = utils.GetQuote(client, instrument); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	request, err := utils.ClosingOrder(portfolioId, position, math.Abs(utils.PositionSize(position)), opts.method, quote, opts.instruments[instrument], opts.slippage)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Side, result.Size, result.Type, result.Price = request.Side, request.Size, request.Type, request.Price
+
+	ctx, cancel := utils.GetContextWithTimeout()
+	defer cancel()
+	order, err := utils.CreateCloseOrder(ctx, client, request)
+	utils.RecordAudit(cmd, request, order, err)
+	if err != nil {
+		result.Error = fmt.Sprintf("cannot create order: %v", err)
+		return result
+	}
+	result.OrderId, result.Status = order.OrderId, order.OrderStatus
+	return result
+}
+
+func checkPanicPortfolio(client *intx.Client, portfolioId string) ([]intx.Order, []intx.Position, error) {
+	orders, err := utils.ListAllOpenOrders(client, portfolioId, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot check open orders: %w", err)
+	}
+	positions, err := utils.GetPositions(client, portfolioId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot check positions: %w", err)
+	}
+	return orders, utils.OpenPositions(positions), nil
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: panicCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Comma separated portfolio IDs or names. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AllFlag,
+					Shorthand:    "a",
+					Usage:        "All portfolios",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ClosePositionsFlag,
+					Shorthand:    "",
+					Usage:        "Also close every position with close-only orders",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.MethodFlag,
+					Shorthand:    "m",
+					Usage:        "Closing order type: market, or limit crossing the touch by --slippage",
+					DefaultValue: utils.CloseMethodMarket,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.SlippageFlag,
+					Shorthand:    "",
+					Usage:        "How far limit closing orders cross the best bid or ask, e.g. 0.5%",
+					DefaultValue: "1%",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TimeoutFlag,
+					Shorthand:    "",
+					Usage:        "How long to keep cancelling and closing what remains",
+					DefaultValue: "30s",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.IntervalFlag,
+					Shorthand:    "",
+					Usage:        "Time between checks, each followed by new cancels and closing orders for what remains",
+					DefaultValue: "2s",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ConcurrencyFlag,
+					Shorthand:    "c",
+					Usage:        "Maximum number of portfolios handled at once",
+					DefaultValue: utils.DefaultConcurrency,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.YesFlag,
+					Shorthand:    "y",
+					Usage:        "Do not ask for confirmation",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -0,0 +1,209 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"time"
+)
+
+var pnlCmd = &cobra.Command{
+	Use:   "pnl",
+	Short: "Report realized and unrealized PnL, fees and funding per instrument.",
+	Long: "Report realized and unrealized PnL, fees and funding per instrument.\n\n" +
+		"All fills of the portfolio are matched into lots so that positions opened before --from " +
+		"are costed correctly; realized PnL, fees and funding are reported for the --from/--to window. " +
+		"Unrealized PnL is computed for current positions at the current mark price. With --to, " +
+		"the lots open at --to are listed instead, without unrealized PnL.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		from, err := utils.GetFlagTimeValue(cmd, utils.FromFlag)
+		if err != nil {
+			return err
+		}
+		to, err := utils.GetFlagTimeValue(cmd, utils.ToFlag)
+		if err != nil {
+			return err
+		}
+
+		fills, err := utils.ListAllFills(client, portfolioId, "")
+		if err != nil {
+			return err
+		}
+
+		var positions []intx.Position
+		var marks map[string]float64
+		if to.IsZero() {
+			if positions, err = utils.GetPositions(client, portfolioId); err != nil {
+				return err
+			}
+
+			var symbols []string
+			for _, p := range positions {
+				if utils.ParseAmountOrZero(p.NetSize) != 0 {
+					symbols = append(symbols, p.Symbol)
+				}
+			}
+			if marks, err = utils.GetMarkPrices(client, symbols); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, "NOTE: unrealized PnL is not reported with --to, since mark prices at that time are not available")
+		}
+
+		funding, err := getFunding(client, portfolioId, from, to)
+		if err != nil {
+			return err
+		}
+
+		report, err := utils.ComputePnl(&utils.PnlInput{
+			PortfolioId: portfolioId,
+			Method:      utils.GetFlagStringValue(cmd, utils.MethodFlag),
+			From:        from,
+			To:          to,
+			Fills:       fills,
+			Positions:   positions,
+			MarkPrices:  marks,
+			Funding:     funding,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot compute PnL: %w", err)
+		}
+
+		table := &utils.Report{
+			Value:   report,
+			Headers: []string{"INSTRUMENT", "REALIZED", "UNREALIZED", "FEES", "FUNDING", "TOTAL", "NET_SIZE", "AVG_COST", "MARK", "FILLS"},
+		}
+		for _, r := range report.Instruments {
+			table.AddRow(
+				r.Instrument,
+				utils.FormatRounded(r.Realized, 8),
+				utils.FormatRounded(r.Unrealized, 8),
+				utils.FormatRounded(r.Fees, 8),
+				utils.FormatRounded(r.Funding, 8),
+				utils.FormatRounded(r.Total, 8),
+				utils.FormatRounded(r.NetSize, 8),
+				utils.FormatRounded(r.AvgCost, 8),
+				utils.FormatRounded(r.MarkPrice, 8),
+				strconv.Itoa(r.FillCount),
+			)
+		}
+		total := report.Total
+		table.AddRow(
+			total.Instrument,
+			utils.FormatRounded(total.Realized, 8),
+			utils.FormatRounded(total.Unrealized, 8),
+			utils.FormatRounded(total.Fees, 8),
+			utils.FormatRounded(total.Funding, 8),
+			utils.FormatRounded(total.Total, 8),
+			"", "", "",
+			strconv.Itoa(total.FillCount),
+		)
+
+		return utils.PrintReport(cmd, table)
+	},
+}
+
+// getFunding returns the funding received (positive) or paid (negative) per
+// instrument symbol in [from, to).
+func getFunding(client *intx.Client, portfolioId string, from, to time.Time) (map[string]float64, error) {
+	filter := utils.TransferFilter{
+		PortfolioIds: portfolioId,
+		Type:         utils.TransferTypeFunding,
+	}
+	if !from.IsZero() {
+		filter.TimeFrom = from.UTC().Format(time.RFC3339)
+	}
+	if !to.IsZero() {
+		filter.TimeTo = to.UTC().Format(time.RFC3339)
+	}
+
+	transfers, err := utils.ListAllTransfers(client, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+
+	symbols, err := utils.GetInstrumentSymbols(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.FundingByInstrument(transfers, portfolioId, symbols, from, to), nil
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: pnlCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "i",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FromFlag,
+					Usage:        "Start of the reporting window (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ToFlag,
+					Usage:        "End of the reporting window, exclusive (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.MethodFlag,
+					Shorthand:    "m",
+					Usage:        "Lot matching method: fifo, lifo or avg",
+					DefaultValue: utils.PnlMethodFifo,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -0,0 +1,239 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	rebalancePollInterval = 2 * time.Second
+	rebalanceWaitTimeout  = 2 * time.Minute
+)
+
+type rebalanceResult struct {
+	Transfer     *utils.RebalanceTransfer `json:"transfer"`
+	TransferUuid string                   `json:"transferUuid,omitempty"`
+	Status       string                   `json:"status"`
+	Error        string                   `json:"error,omitempty"`
+}
+
+var rebalanceCmd = &cobra.Command{
+	Use:         "rebalance",
+	Short:       "Rebalance collateral between portfolios according to a policy file.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := utils.InitClientAndPortfolioId(cmd, false)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		policy, err := utils.LoadRebalancePolicy(utils.GetFlagStringValue(cmd, utils.PolicyFlag))
+		if err != nil {
+			return err
+		}
+
+		allPortfolios, err := utils.ListPortfolios(client)
+		if err != nil {
+			return err
+		}
+
+		var portfolios []*intx.Portfolio
+		for _, t := range policy.Portfolios {
+			p, err := utils.FindPortfolio(allPortfolios, t.Portfolio)
+			if err != nil {
+				return err
+			}
+			portfolios = append(portfolios, p)
+		}
+
+		states, errs := utils.FetchPortfolioStates(client, portfolios, utils.DefaultConcurrency)
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+
+		plan := utils.PlanRebalance(policy, states)
+
+		itemReport := &utils.Report{
+			Headers: []string{"PORTFOLIO", "NAME", "BALANCE", "AVAILABLE", "MARGIN_RATIO", "CHANGE", "REASON"},
+		}
+		for _, item := range plan.Items {
+			itemReport.AddRow(
+				item.PortfolioId,
+				item.Name,
+				utils.FormatRounded(item.Balance, 2),
+				utils.FormatRounded(item.Available, 2),
+				utils.FormatRounded(item.MarginRatio*100, 2)+"%",
+				utils.FormatRounded(item.Change, 2),
+				item.Reason,
+			)
+		}
+
+		transferReport := &utils.Report{Headers: []string{"FROM", "TO", "ASSET", "AMOUNT"}}
+		for _, t := range plan.Transfers {
+			transferReport.AddRow(t.From, t.To, t.Asset, utils.FormatAmount(t.Amount))
+		}
+
+		if err := utils.PrintReports(cmd, plan, itemReport, transferReport); err != nil {
+			return err
+		}
+
+		if plan.Shortfall > 0 {
+			fmt.Fprintf(os.Stderr, "WARNING: surpluses cannot cover deficits, %s %s short\n", utils.FormatRounded(plan.Shortfall, 2), plan.Asset)
+		}
+
+		if len(plan.Transfers) == 0 {
+			fmt.Fprintln(os.Stderr, "Nothing to rebalance")
+			return nil
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool(utils.DryRunFlag); dryRun {
+			return nil
+		}
+
+		if yes, _ := cmd.Flags().GetBool(utils.YesFlag); !yes {
+			if !utils.Confirm(fmt.Sprintf("Execute %d transfers?", len(plan.Transfers))) {
+				return errors.New("rebalance cancelled")
+			}
+		}
+
+		results := executeRebalance(cmd, client, plan)
+
+		resultReport := &utils.Report{Headers: []string{"FROM", "TO", "ASSET", "AMOUNT", "TRANSFER_UUID", "STATUS", "ERROR"}}
+		failed := 0
+		for _, r := range results {
+			if !strings.EqualFold(r.Status, utils.TransferStatusProcessed) {
+				failed++
+			}
+			resultReport.AddRow(r.Transfer.From, r.Transfer.To, r.Transfer.Asset, utils.FormatAmount(r.Transfer.Amount), r.TransferUuid, r.Status, r.Error)
+		}
+
+		fmt.Println()
+		if err := utils.PrintReports(cmd, results, resultReport); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d transfers were not verified as processed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// executeRebalance submits the transfers in order and verifies each one
+// through GetTransfer before moving on.
+func executeRebalance(cmd *cobra.Command, client *intx.Client, plan *utils.RebalancePlan) []*rebalanceResult {
+	matched := map[string]bool{}
+	var results []*rebalanceResult
+
+	for _, t := range plan.Transfers {
+		result := &rebalanceResult{Transfer: t}
+		results = append(results, result)
+
+		request := &intx.CreatePortfolioTransferRequest{
+			From:    t.From,
+			To:      t.To,
+			AssetId: t.Asset,
+			Amount:  utils.FormatAmount(t.Amount),
+		}
+
+		submitted := time.Now()
+		ctx, cancel := utils.GetContextWithTimeout()
+		response, err := client.CreatePortfolioTransfer(ctx, request)
+		cancel()
+		utils.RecordAudit(cmd, request, response, err)
+		if err == nil && !response.Success {
+			err = errors.New("transfer was not accepted")
+		}
+		if err != nil {
+			result.Status = utils.TransferStatusFailed
+			result.Error = err.Error()
+			continue
+		}
+
+		transfer, err := utils.FindPortfolioTransfer(client, request, submitted, matched)
+		if err != nil || transfer == nil {
+			result.Status = "UNVERIFIED"
+			result.Error = "cannot find submitted transfer"
+			if err != nil {
+				result.Error = err.Error()
+			}
+			continue
+		}
+		matched[transfer.TransferUuid] = true
+		result.TransferUuid = transfer.TransferUuid
+
+		if transfer, err = utils.WaitForTransfer(client, transfer.TransferUuid, rebalancePollInterval, rebalanceWaitTimeout); err != nil {
+			result.Status = "UNVERIFIED"
+			result.Error = err.Error()
+			continue
+		}
+		result.Status = transfer.Status
+	}
+	return results
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: rebalanceCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PolicyFlag,
+					Usage:        "Path of the rebalance policy YAML file (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.DryRunFlag,
+					Usage:        "Show the plan without executing it",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.YesFlag,
+					Shorthand:    "y",
+					Usage:        "Execute the plan without asking for confirmation",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -0,0 +1,257 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var reconcileStatuses = []string{
+	utils.ReconcileMatched,
+	utils.ReconcileMismatched,
+	utils.ReconcileMissingOnExchange,
+	utils.ReconcileMissingInLedger,
+	utils.ReconcileDuplicateInLedger,
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile exchange fills against an internal ledger and exit nonzero on breaks.",
+	Long: "Reconcile exchange fills against an internal ledger and exit nonzero on breaks.\n\n" +
+		"The ledger is a CSV file with a header row containing quantity (or qty, size) and price columns, " +
+		"fill_id and/or client_order_id, and optionally instrument (or symbol), side and time. " +
+		"Bookings are matched on fill_id when given, otherwise on client_order_id, price and size. " +
+		"Bookings without a time are always included; the others are limited to --from/--to like the fills.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool(utils.AllFlag)
+
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, !all)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		tolerances := utils.ReconcileTolerances{}
+		if tolerances.Price, err = utils.ParseTolerance(utils.GetFlagStringValue(cmd, utils.PriceToleranceFlag)); err != nil {
+			return err
+		}
+		if tolerances.Quantity, err = utils.ParseTolerance(utils.GetFlagStringValue(cmd, utils.SizeToleranceFlag)); err != nil {
+			return err
+		}
+
+		from, err := utils.GetFlagTimeValue(cmd, utils.FromFlag)
+		if err != nil {
+			return err
+		}
+		to, err := utils.GetFlagTimeValue(cmd, utils.ToFlag)
+		if err != nil {
+			return err
+		}
+
+		ledger, err := utils.ReadLedger(utils.GetFlagStringValue(cmd, utils.LedgerFlag))
+		if err != nil {
+			return err
+		}
+		var bookings []*utils.LedgerFill
+		for _, l := range ledger {
+			if l.Time.IsZero() || utils.InTimeRange(l.Time, from, to) {
+				bookings = append(bookings, l)
+			}
+		}
+
+		portfolios, err := utils.ListPortfolios(client)
+		if err != nil {
+			return err
+		}
+		if !all {
+			if portfolios, err = utils.SelectPortfolios(portfolios, portfolioId); err != nil {
+				return err
+			}
+		}
+		var portfolioIds []string
+		for _, p := range portfolios {
+			portfolioIds = append(portfolioIds, p.PortfolioId)
+		}
+
+		timeFrom := ""
+		if !from.IsZero() {
+			timeFrom = from.UTC().Format(time.RFC3339)
+		}
+		allFills, err := utils.ListAllFills(client, strings.Join(portfolioIds, ","), timeFrom)
+		if err != nil {
+			return err
+		}
+		var fills []intx.Fill
+		for _, f := range allFills {
+			t, err := utils.ParseTime(f.EventTime)
+			if err != nil {
+				return fmt.Errorf("fill %s: %w", f.FillId, err)
+			}
+			if utils.InTimeRange(t, from, to) {
+				fills = append(fills, f)
+			}
+		}
+
+		result := utils.Reconcile(bookings, fills, tolerances)
+
+		showMatched, _ := cmd.Flags().GetBool(utils.MatchedFlag)
+		if !showMatched {
+			breaks := []*utils.ReconcileItem{}
+			for _, item := range result.Items {
+				if item.Status != utils.ReconcileMatched {
+					breaks = append(breaks, item)
+				}
+			}
+			result.Items = breaks
+		}
+
+		summary := &utils.Report{Headers: []string{"STATUS", "COUNT"}}
+		for _, status := range reconcileStatuses {
+			summary.AddRow(status, strconv.Itoa(result.Counts[status]))
+		}
+
+		details := &utils.Report{
+			Headers: []string{"STATUS", "LINE", "FILL_ID", "CLIENT_ORDER_ID", "INSTRUMENT", "SIDE", "LEDGER_QTY", "EXCHANGE_QTY", "LEDGER_PRICE", "EXCHANGE_PRICE", "DIFFERENCES"},
+		}
+		for _, item := range result.Items {
+			var line, ledgerQty, exchangeQty, ledgerPrice, exchangePrice string
+			ledgerFill, exchangeFill := &utils.LedgerFill{}, &intx.Fill{}
+			if item.Ledger != nil {
+				ledgerFill = item.Ledger
+				line = strconv.Itoa(ledgerFill.Line)
+				ledgerQty, ledgerPrice = utils.FormatAmount(ledgerFill.Quantity), utils.FormatAmount(ledgerFill.Price)
+			}
+			if item.Exchange != nil {
+				exchangeFill = item.Exchange
+				exchangeQty, exchangePrice = exchangeFill.FillQty, exchangeFill.FillPrice
+			}
+			details.AddRow(item.Status, line,
+				reconcileValue(ledgerFill.FillId, exchangeFill.FillId),
+				reconcileValue(ledgerFill.ClientOrderId, exchangeFill.ClientOrderId),
+				reconcileValue(ledgerFill.Instrument, exchangeFill.Symbol),
+				reconcileValue(ledgerFill.Side, exchangeFill.Side),
+				ledgerQty, exchangeQty, ledgerPrice, exchangePrice, strings.Join(item.Differences, ","))
+		}
+
+		if err := utils.PrintReports(cmd, result, summary, details); err != nil {
+			return err
+		}
+
+		if breaks := result.Breaks(); breaks > 0 {
+			// Breaks are a result, not a usage error.
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d reconciliation breaks", breaks)
+		}
+		return nil
+	},
+}
+
+// reconcileValue shows a field once, or as ledger/exchange when both sides
+// have a different value.
+func reconcileValue(ledger, exchange string) string {
+	switch {
+	case ledger == "":
+		return exchange
+	case exchange == "" || strings.EqualFold(ledger, exchange):
+		return ledger
+	default:
+		return ledger + "/" + exchange
+	}
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: reconcileCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.LedgerFlag,
+					Shorthand:    "l",
+					Usage:        "Ledger CSV file (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Comma separated portfolio IDs or names. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AllFlag,
+					Shorthand:    "a",
+					Usage:        "Reconcile fills of all portfolios",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FromFlag,
+					Usage:        "Start of the reconciliation window (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ToFlag,
+					Usage:        "End of the reconciliation window, exclusive (RFC3339 or YYYY-MM-DD)",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.PriceToleranceFlag,
+					Usage:        "Accepted price difference, an amount or a percentage such as 0.01%",
+					DefaultValue: "0",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.SizeToleranceFlag,
+					Usage:        "Accepted size difference, an amount or a percentage such as 0.01%",
+					DefaultValue: "0",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.MatchedFlag,
+					Shorthand:    "m",
+					Usage:        "Also list matched fills, not only breaks",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/coinbase-samples/intx-cli/utils"
 	"os"
 
@@ -26,15 +27,27 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "intxctl",
 	Short: "Root of INTX cli",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return utils.WarnIfProduction(cmd)
+	},
 }
 
 func Execute() {
 	err := rootCmd.Execute()
+	if err == nil {
+		if err = utils.TakeAuditFailure(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+	}
 	if err != nil {
-		os.Exit(1)
+		os.Exit(utils.ExitCode(err))
 	}
 }
 
 func init() {
 	rootCmd.Flags().BoolP(utils.ToggleFlag, "t", false, "Help message for toggle")
+
+	rootCmd.PersistentFlags().String(utils.ProfileFlag, "", "Config profile to use. Uses INTX_PROFILE or the default profile if blank")
+	rootCmd.PersistentFlags().String(utils.EnvFlag, "", "Target environment: production, sandbox or custom. Uses INTX_ENV or the profile if blank")
+	rootCmd.PersistentFlags().String(utils.BaseUrlFlag, "", "API base URL. Uses INTX_BASE_URL or the profile if blank")
 }
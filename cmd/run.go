@@ -0,0 +1,192 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run PLAYBOOK",
+	Short: "Run the steps of a playbook file.",
+	Long: "Run the steps of a playbook file.\n\n" +
+		"Each step runs an intxctl command line, rendered as a Go template over .vars, the JSON output of earlier " +
+		"steps as .steps.NAME.output and the loop item of steps with foreach. Steps run in order and the playbook " +
+		"stops at the first failing step unless it sets continueOnError. With --dry-run, read-only steps run so " +
+		"later steps can use their output, while commands that change state are only printed.",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		playbook, err := utils.LoadPlaybook(args[0])
+		if err != nil {
+			return err
+		}
+
+		overrides := map[string]string{}
+		values, _ := cmd.Flags().GetStringArray(utils.VarFlag)
+		for _, v := range values {
+			name, value, ok := strings.Cut(v, "=")
+			if !ok || name == "" {
+				return fmt.Errorf("invalid --%s %s: use name=value", utils.VarFlag, v)
+			}
+			overrides[name] = value
+		}
+
+		dryRun, _ := cmd.Flags().GetBool(utils.DryRunFlag)
+		r := &playbookRunner{
+			context:   utils.NewPlaybookContext(playbook, overrides),
+			inherited: inheritedGlobalFlags(cmd),
+			dryRun:    dryRun,
+			total:     len(playbook.Steps),
+		}
+		for i, step := range playbook.Steps {
+			if err := r.runStep(i+1, step); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("step %s failed: %w", step.Name, err)
+			}
+		}
+		return nil
+	},
+}
+
+type playbookRunner struct {
+	context   *utils.PlaybookContext
+	inherited []string
+	dryRun    bool
+	total     int
+}
+
+func (r *playbookRunner) runStep(n int, step *utils.PlaybookStep) error {
+	enabled, err := r.context.Enabled(step)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		fmt.Fprintf(os.Stderr, "==> [%d/%d] %s: skipped\n", n, r.total, step.Name)
+		r.context.Steps[step.Name] = utils.PlaybookStepResult{"skipped": true}
+		return nil
+	}
+
+	items, err := r.context.Items(step)
+	if err != nil {
+		return err
+	}
+	loop := items != nil
+	if !loop {
+		items = []interface{}{nil}
+	}
+
+	var outputs, stdouts []interface{}
+	var failures []string
+	for _, item := range items {
+		argv, err := r.context.Command(step, item)
+		if err != nil {
+			return err
+		}
+		c, err := checkInProcessCommand(argv, "run")
+		if err != nil {
+			return err
+		}
+
+		label := fmt.Sprintf("==> [%d/%d] %s", n, r.total, step.Name)
+		if loop {
+			label += fmt.Sprintf(" (%s=%v)", step.As, item)
+		}
+		if r.dryRun && utils.IsMutatingCommand(c) {
+			fmt.Fprintf(os.Stderr, "%s: would run intxctl %s\n", label, strings.Join(argv, " "))
+			outputs = append(outputs, nil)
+			stdouts = append(stdouts, "")
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: intxctl %s\n", label, strings.Join(argv, " "))
+
+		stdout, err := runCapturingOutput(append(argv, r.inherited...))
+		fmt.Print(stdout)
+		outputs = append(outputs, utils.ParseCommandOutput(stdout))
+		stdouts = append(stdouts, stdout)
+		if err != nil {
+			if !step.ContinueOnError {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "WARNING: %s: %v\n", step.Name, err)
+			failures = append(failures, err.Error())
+		}
+	}
+
+	result := utils.PlaybookStepResult{"skipped": false, "error": strings.Join(failures, "; ")}
+	if loop {
+		result["output"], result["stdout"] = outputs, stdouts
+	} else {
+		result["output"], result["stdout"] = outputs[0], stdouts[0]
+	}
+	r.context.Steps[step.Name] = result
+	return nil
+}
+
+// runCapturingOutput runs a command in-process and returns what it wrote to
+// stdout. Stderr is passed through.
+func runCapturingOutput(args []string) (string, error) {
+	out, err := os.CreateTemp("", "intxctl-step-*")
+	if err != nil {
+		return "", fmt.Errorf("cannot create output file: %w", err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	runErr := executeInProcess(context.Background(), args, out, os.Stderr)
+	stdout, err := os.ReadFile(out.Name())
+	if err != nil {
+		return "", fmt.Errorf("cannot read output: %w", err)
+	}
+	return string(stdout), runErr
+}
+
+// inheritedGlobalFlags returns the global flags given to a command, for
+// passing on to the commands it runs.
+func inheritedGlobalFlags(cmd *cobra.Command) []string {
+	var flags []string
+	for _, name := range []string{utils.ProfileFlag, utils.EnvFlag, utils.BaseUrlFlag} {
+		if cmd.Flags().Changed(name) {
+			flags = append(flags, fmt.Sprintf("--%s=%s", name, utils.GetFlagStringValue(cmd, name)))
+		}
+	}
+	return flags
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: runCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.DryRunFlag,
+					Usage:        "Run read-only steps and print the commands that would change state without running them",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+	runCmd.Flags().StringArray(utils.VarFlag, nil, "Set a playbook variable as name=value, overriding vars in the file. Repeatable")
+}
@@ -0,0 +1,248 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Run intxctl commands on cron expressions or intervals.",
+	Long: "Run intxctl commands on cron expressions or intervals.\n\n" +
+		"Jobs run in this process, one at a time, sharing one client per environment. Output of each run is " +
+		"appended to the job's log file, which is rotated by size. A job that is due while its previous run is " +
+		"queued or running is skipped. A run that exceeds its timeout has its API calls cancelled. " +
+		"Use scheduler status to see the last run of every job.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := utils.LoadSchedulerConfig(utils.GetFlagStringValue(cmd, utils.ConfigFlag))
+		if err != nil {
+			return err
+		}
+		for _, job := range config.Jobs {
+			if _, err := checkInProcessCommand(job.Args); err != nil {
+				return fmt.Errorf("job %s: %w", job.Name, err)
+			}
+		}
+
+		utils.ReuseClients()
+		s := &jobScheduler{
+			config: config,
+			state: &utils.SchedulerState{
+				Pid:       os.Getpid(),
+				StartedAt: time.Now().UTC(),
+				Jobs:      map[string]*utils.JobState{},
+			},
+			busy:   map[string]bool{},
+			queue:  make(chan *utils.ScheduledJob, len(config.Jobs)),
+			stderr: os.Stderr,
+		}
+		return s.run()
+	},
+}
+
+var errJobTimeout = errors.New("timed out")
+
+type jobScheduler struct {
+	config *utils.SchedulerConfig
+	state  *utils.SchedulerState
+	mu     sync.Mutex
+	busy   map[string]bool
+	queue  chan *utils.ScheduledJob
+	stderr *os.File
+}
+
+func (s *jobScheduler) run() error {
+	now := time.Now()
+	next := map[*utils.ScheduledJob]time.Time{}
+	for _, job := range s.config.Jobs {
+		next[job] = job.Next(now)
+		s.state.Jobs[job.Name] = &utils.JobState{
+			Schedule: job.ScheduleText(),
+			Status:   utils.JobStatusNeverRun,
+			NextRun:  next[job].UTC(),
+			Output:   job.Output,
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(s.config.State), 0700); err != nil {
+		return fmt.Errorf("cannot create %s: %w", filepath.Dir(s.config.State), err)
+	}
+	if err := s.saveState(); err != nil {
+		return err
+	}
+	s.logf("Scheduling %d jobs, state in %s", len(s.config.Jobs), s.config.State)
+
+	go func() {
+		for job := range s.queue {
+			s.execute(job)
+		}
+	}()
+
+	for {
+		var wake time.Time
+		for _, t := range next {
+			if wake.IsZero() || t.Before(wake) {
+				wake = t
+			}
+		}
+		time.Sleep(time.Until(wake))
+
+		now := time.Now()
+		s.mu.Lock()
+		for _, job := range s.config.Jobs {
+			if now.Before(next[job]) {
+				continue
+			}
+			next[job] = job.Next(now)
+			js := s.state.Jobs[job.Name]
+			js.NextRun = next[job].UTC()
+			if s.busy[job.Name] {
+				js.Skipped++
+				s.logf("Skipping %s: previous run has not finished", job.Name)
+				continue
+			}
+			s.busy[job.Name] = true
+			s.queue <- job
+		}
+		err := s.saveStateLocked()
+		s.mu.Unlock()
+		if err != nil {
+			s.logf("WARNING: %v", err)
+		}
+	}
+}
+
+// execute runs a job in-process with stdout and stderr redirected to its
+// output file. Only one job runs at a time since the redirection is process
+// wide.
+func (s *jobScheduler) execute(job *utils.ScheduledJob) {
+	start := time.Now()
+	s.update(job, func(js *utils.JobState) {
+		js.Status = utils.JobStatusRunning
+		js.LastStart = start.UTC()
+	})
+
+	err := s.runCommand(job)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	status := utils.JobStatusOk
+	switch {
+	case errors.Is(err, errJobTimeout):
+		status = utils.JobStatusTimeout
+	case err != nil:
+		status = utils.JobStatusFailed
+	}
+	s.update(job, func(js *utils.JobState) {
+		js.Status = status
+		js.LastDuration = elapsed.String()
+		js.LastError = ""
+		js.Runs++
+		if err != nil {
+			js.LastError = err.Error()
+			js.Failures++
+		}
+	})
+
+	s.mu.Lock()
+	delete(s.busy, job.Name)
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logf("%s %s after %s: %v", job.Name, status, elapsed, err)
+	} else {
+		s.logf("%s ok in %s", job.Name, elapsed)
+	}
+}
+
+func (s *jobScheduler) runCommand(job *utils.ScheduledJob) (err error) {
+	out, err := utils.OpenJobOutput(job.Output, s.config.MaxLogSize, s.config.LogBackups)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), job.Timeout)
+	defer cancel()
+
+	fmt.Fprintf(out, "=== %s %s: intxctl %s\n", time.Now().UTC().Format(time.RFC3339), job.Name, strings.Join(job.Args, " "))
+	err = executeInProcess(ctx, job.Args, out, out)
+	if ctx.Err() != nil {
+		if err != nil {
+			err = fmt.Errorf("%w after %s: %v", errJobTimeout, job.Timeout, err)
+		} else {
+			err = fmt.Errorf("%w after %s", errJobTimeout, job.Timeout)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(out, "=== error: %v\n", err)
+	}
+	return err
+}
+
+func (s *jobScheduler) update(job *utils.ScheduledJob, change func(*utils.JobState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(s.state.Jobs[job.Name])
+	if err := s.saveStateLocked(); err != nil {
+		s.logf("WARNING: %v", err)
+	}
+}
+
+func (s *jobScheduler) saveState() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveStateLocked()
+}
+
+func (s *jobScheduler) saveStateLocked() error {
+	s.state.UpdatedAt = time.Now().UTC()
+	if err := utils.WriteJsonFile(s.config.State, s.state); err != nil {
+		return fmt.Errorf("cannot save scheduler state: %w", err)
+	}
+	return nil
+}
+
+func (s *jobScheduler) logf(format string, args ...interface{}) {
+	fmt.Fprintf(s.stderr, "%s  %s\n", time.Now().UTC().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: schedulerCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.ConfigFlag,
+					Usage:        "YAML file with the jobs to run (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
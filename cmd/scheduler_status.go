@@ -0,0 +1,118 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var schedulerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the last run and next run of every scheduled job.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statePath, err := utils.GetSchedulerStatePath()
+		if err != nil {
+			return err
+		}
+		if path := utils.GetFlagStringValue(cmd, utils.ConfigFlag); path != "" {
+			config, err := utils.LoadSchedulerConfig(path)
+			if err != nil {
+				return err
+			}
+			statePath = config.State
+		}
+
+		state, err := utils.LoadSchedulerState(statePath)
+		if err != nil {
+			return err
+		}
+		if len(state.Jobs) == 0 {
+			return fmt.Errorf("no scheduler state in %s", statePath)
+		}
+		fmt.Fprintf(os.Stderr, "Scheduler pid %d started %s, last updated %s\n",
+			state.Pid, state.StartedAt.Format(time.RFC3339), state.UpdatedAt.Format(time.RFC3339))
+
+		names := make([]string, 0, len(state.Jobs))
+		for name := range state.Jobs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		report := &utils.Report{
+			Value:   state,
+			Headers: []string{"JOB", "SCHEDULE", "STATUS", "LAST_START", "DURATION", "NEXT_RUN", "RUNS", "FAILURES", "SKIPPED", "LAST_ERROR"},
+		}
+		for _, name := range names {
+			js := state.Jobs[name]
+			lastStart := ""
+			if !js.LastStart.IsZero() {
+				lastStart = js.LastStart.Format(time.RFC3339)
+			}
+			report.AddRow(
+				name,
+				js.Schedule,
+				js.Status,
+				lastStart,
+				js.LastDuration,
+				js.NextRun.Format(time.RFC3339),
+				strconv.Itoa(js.Runs),
+				strconv.Itoa(js.Failures),
+				strconv.Itoa(js.Skipped),
+				js.LastError,
+			)
+		}
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: schedulerStatusCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.ConfigFlag,
+					Usage:        "Jobs file whose state to show. Uses the default state file if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(schedulerCmd, cmdConfigs)
+}
@@ -24,8 +24,9 @@ import (
 )
 
 var getMarginOverrideCmd = &cobra.Command{
-	Use:   "set-margin-override",
-	Short: "Set margin override for portfolio.",
+	Use:         "set-margin-override",
+	Short:       "Set margin override for portfolio.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -41,6 +42,7 @@ var getMarginOverrideCmd = &cobra.Command{
 		}
 
 		response, err := client.SetMarginOverride(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot set margin override: %w", err)
 		}
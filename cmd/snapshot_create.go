@@ -0,0 +1,136 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Write balances, positions, open orders and summary of one or all portfolios to a snapshot file.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool(utils.AllFlag)
+
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, !all)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		environment, err := utils.ResolveEnvironment(cmd)
+		if err != nil {
+			return err
+		}
+
+		portfolios := []*intx.Portfolio{{PortfolioId: portfolioId}}
+		if all {
+			if portfolios, err = utils.ListPortfolios(client); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		path := utils.GetFlagStringValue(cmd, utils.OutputFlag)
+		format := utils.GetFlagStringValue(cmd, utils.OutputFormatFlag)
+		if path == "" {
+			if format == "" {
+				format = utils.SnapshotFormatJson
+			}
+			extension := format
+			if format == utils.SnapshotFormatSqlite {
+				extension = "db"
+			}
+			path = fmt.Sprintf("snapshot-%s.%s", now.Format("20060102T150405Z"), extension)
+		} else if format == "" {
+			format = utils.SnapshotFormatForPath(path)
+		}
+
+		concurrency, _ := cmd.Flags().GetInt(utils.ConcurrencyFlag)
+		states, errs := utils.FetchPortfolioStates(client, portfolios, concurrency)
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+
+		snapshot := &utils.Snapshot{
+			Version:     utils.SnapshotVersion,
+			CreatedAt:   now.Format(time.RFC3339),
+			Profile:     environment.Profile,
+			Environment: environment.Name,
+			BaseUrl:     environment.BaseUrl,
+			Portfolios:  states,
+		}
+
+		if err := utils.WriteSnapshot(path, format, snapshot); err != nil {
+			return fmt.Errorf("cannot write snapshot: %w", err)
+		}
+
+		fmt.Printf("Wrote snapshot of %d portfolios to %s\n", len(states), path)
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: snapshotCreateCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AllFlag,
+					Shorthand:    "a",
+					Usage:        "Snapshot all portfolios",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFlag,
+					Shorthand:    "o",
+					Usage:        "Snapshot file. Defaults to snapshot-<timestamp>.json or .db in the current directory",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "f",
+					Usage:        "Snapshot format: json or sqlite. Derived from the output file extension if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.ConcurrencyFlag,
+					Shorthand:    "c",
+					Usage:        "Maximum number of portfolios fetched at once",
+					DefaultValue: utils.DefaultConcurrency,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(snapshotCmd, cmdConfigs)
+}
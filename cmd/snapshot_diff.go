@@ -0,0 +1,93 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff A B",
+	Short: "Report balance, position and open order changes between two snapshots.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tolerance, err := utils.ParseTolerance(utils.GetFlagStringValue(cmd, utils.ToleranceFlag))
+		if err != nil {
+			return err
+		}
+
+		before, err := utils.ReadSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		after, err := utils.ReadSnapshot(args[1])
+		if err != nil {
+			return err
+		}
+
+		changes := utils.DiffSnapshots(before, after, tolerance)
+
+		report := &utils.Report{
+			Value:   changes,
+			Headers: []string{"PORTFOLIO", "CATEGORY", "KEY", "FIELD", "CHANGE", "BEFORE", "AFTER"},
+		}
+		for _, c := range changes {
+			report.AddRow(c.PortfolioId, c.Category, c.Key, c.Field, c.Change, c.Before, c.After)
+		}
+
+		if len(changes) == 0 {
+			fmt.Fprintf(os.Stderr, "No differences between %s (%s) and %s (%s)\n", args[0], before.CreatedAt, args[1], after.CreatedAt)
+		}
+		return utils.PrintReport(cmd, report)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: snapshotDiffCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.ToleranceFlag,
+					Shorthand:    "t",
+					Usage:        "Ignore numeric changes up to this amount, or this percentage when suffixed with %",
+					DefaultValue: "0",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(snapshotCmd, cmdConfigs)
+}
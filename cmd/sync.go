@@ -0,0 +1,136 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror orders, fills, transfers, positions and balances into the local SQLite journal.",
+	Long: "Mirror orders, fills, transfers, positions and balances into the local SQLite journal.\n\n" +
+		"Fills and transfers are fetched incrementally from where the previous sync of each portfolio " +
+		"stopped. Query the journal with `db query`.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool(utils.AllFlag)
+
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, !all)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		portfolios := []*intx.Portfolio{{PortfolioId: portfolioId}}
+		if all {
+			if portfolios, err = utils.ListPortfolios(client); err != nil {
+				return err
+			}
+		}
+
+		path, err := utils.GetJournalPath(utils.GetFlagStringValue(cmd, utils.DbFlag))
+		if err != nil {
+			return err
+		}
+		db, err := utils.OpenJournal(path)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		// Portfolios are synced one at a time to stay well within rate limits.
+		var results []*utils.JournalSyncStats
+		failed := 0
+		for _, p := range portfolios {
+			stats, err := utils.SyncJournal(db, client, p)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+				failed++
+				continue
+			}
+			results = append(results, stats)
+		}
+
+		report := &utils.Report{
+			Value:   results,
+			Headers: []string{"PORTFOLIO", "NAME", "ORDERS", "FILLS", "TRANSFERS", "POSITIONS", "BALANCES"},
+		}
+		for _, s := range results {
+			report.AddRow(s.PortfolioId, s.Name, strconv.Itoa(s.Orders), strconv.Itoa(s.Fills),
+				strconv.Itoa(s.Transfers), strconv.Itoa(s.Positions), strconv.Itoa(s.Balances))
+		}
+		if err := utils.PrintReport(cmd, report); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("cannot sync %d of %d portfolios", failed, len(portfolios))
+		}
+		fmt.Fprintf(os.Stderr, "Synced %d portfolios to %s\n", len(results), path)
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: syncCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.AllFlag,
+					Shorthand:    "a",
+					Usage:        "Sync all portfolios",
+					DefaultValue: false,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.DbFlag,
+					Usage:        "Journal database file. Defaults to journal.db in the CLI home directory",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.OutputFormatFlag,
+					Shorthand:    "o",
+					Usage:        "Output format: table, csv or json",
+					DefaultValue: utils.OutputFormatTable,
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
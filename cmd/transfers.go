@@ -0,0 +1,134 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"time"
+)
+
+const transferBackoffFactor = 1.5
+
+var transfersCmd = &cobra.Command{
+	Use:   "transfers",
+	Short: "Track deposits, withdrawals and internal transfers.",
+}
+
+type transferWatchOptions struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	timeout     time.Duration
+}
+
+func getTransferWatchOptions(cmd *cobra.Command) (*transferWatchOptions, error) {
+	opts := &transferWatchOptions{}
+	var err error
+	if opts.interval, err = utils.GetFlagDurationValue(cmd, utils.IntervalFlag); err != nil {
+		return nil, err
+	}
+	if opts.maxInterval, err = utils.GetFlagDurationValue(cmd, utils.MaxIntervalFlag); err != nil {
+		return nil, err
+	}
+	if opts.timeout, err = utils.GetFlagDurationValue(cmd, utils.TimeoutFlag); err != nil {
+		return nil, err
+	}
+	if opts.interval <= 0 {
+		return nil, fmt.Errorf("--%s must be positive", utils.IntervalFlag)
+	}
+	if opts.maxInterval < opts.interval {
+		opts.maxInterval = opts.interval
+	}
+	return opts, nil
+}
+
+// watchTransfers polls until no watched transfer is pending or the timeout
+// elapses, printing every change to out. The interval backs off while nothing
+// changes. Errors after the first poll are reported and retried. It stops when
+// the base context of API calls is done.
+func watchTransfers(opts *transferWatchOptions, tracker *utils.TransferTracker, out io.Writer, poll func() ([]*utils.TransferDetails, error)) error {
+	var deadline time.Time
+	if opts.timeout > 0 {
+		deadline = time.Now().Add(opts.timeout)
+	}
+	backoff := &utils.Backoff{Initial: opts.interval, Max: opts.maxInterval, Factor: transferBackoffFactor}
+	ctx := utils.BaseContext()
+
+	for first := true; ; first = false {
+		transfers, err := poll()
+		if err != nil && (first || ctx.Err() != nil) {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+
+		for _, d := range transfers {
+			if previous, changed := tracker.Update(d); changed {
+				fmt.Fprintln(out, utils.FormatTransferTransition(previous, d))
+				backoff.Reset()
+			}
+		}
+		if len(tracker.Pending()) == 0 {
+			return tracker.Outcome(false)
+		}
+
+		wait := backoff.Next()
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return tracker.Outcome(true)
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+		if err := utils.Sleep(ctx, wait); err != nil {
+			return fmt.Errorf("stopped watching transfers: %w", err)
+		}
+	}
+}
+
+func transferWatchFlagConfigs() []utils.FlagConfig {
+	return []utils.FlagConfig{
+		{
+			FlagName:     utils.TimeoutFlag,
+			Usage:        "Give up after this duration, e.g. 30m. 0 waits indefinitely",
+			DefaultValue: "30m",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.IntervalFlag,
+			Usage:        "Initial poll interval, e.g. 2s",
+			DefaultValue: "2s",
+			Required:     false,
+		},
+		{
+			FlagName:     utils.MaxIntervalFlag,
+			Usage:        "Longest poll interval reached by backing off while nothing changes",
+			DefaultValue: "1m",
+			Required:     false,
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(transfersCmd)
+}
@@ -0,0 +1,161 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+const transferWatchLookback = 24 * time.Hour
+
+var transfersWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch pending transfers and print each status change until they complete.",
+	Long: "Watch pending transfers and print each status change until they complete.\n\n" +
+		"Without --transfer-id, the pending transfers of the portfolio created since --from (default 24 hours ago) " +
+		"are watched, as well as transfers created while watching. Exit status is 0 when all watched transfers " +
+		"were processed, 2 when any failed and 3 on timeout.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		transferIds := utils.GetFlagStringValue(cmd, utils.TransferIdFlag)
+
+		client, portfolioIds, err := utils.InitClientAndPortfolioId(cmd, transferIds == "")
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		opts, err := getTransferWatchOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		from, err := utils.GetFlagTimeValue(cmd, utils.FromFlag)
+		if err != nil {
+			return err
+		}
+		if from.IsZero() {
+			from = start.Add(-transferWatchLookback)
+		}
+		transferType := strings.ToUpper(utils.GetFlagStringValue(cmd, utils.TypeFlag))
+
+		tracker := utils.NewTransferTracker()
+		for _, id := range strings.Split(transferIds, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				tracker.Track(id)
+			}
+		}
+
+		poll := func() ([]*utils.TransferDetails, error) {
+			if transferIds == "" {
+				if err := discoverTransfers(client, tracker, portfolioIds, transferType, from, start); err != nil {
+					return nil, err
+				}
+			}
+
+			var transfers []*utils.TransferDetails
+			for _, uuid := range tracker.Pending() {
+				d, err := utils.GetTransferDetails(client, uuid)
+				if err != nil {
+					return transfers, err
+				}
+				transfers = append(transfers, d)
+			}
+			return transfers, nil
+		}
+
+		err = watchTransfers(opts, tracker, os.Stdout, poll)
+		if len(tracker.Transfers()) == 0 && err == nil {
+			fmt.Fprintln(os.Stderr, "No pending transfers")
+		}
+		if err != nil {
+			cmd.SilenceUsage = true
+		}
+		return err
+	},
+}
+
+// discoverTransfers tracks transfers of the portfolios that are pending or
+// were created after start.
+func discoverTransfers(client *intx.Client, tracker *utils.TransferTracker, portfolioIds, transferType string, from, start time.Time) error {
+	transfers, err := utils.ListAllTransfers(client, utils.TransferFilter{
+		PortfolioIds: portfolioIds,
+		Type:         transferType,
+		TimeFrom:     from.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range transfers {
+		if tracker.Tracks(t.TransferUuid) {
+			continue
+		}
+		created, err := utils.ParseTime(t.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("transfer %s: %w", t.TransferUuid, err)
+		}
+		if !utils.IsTransferFinal(t.Status) || !created.Before(start) {
+			tracker.Track(t.TransferUuid)
+		}
+	}
+	return nil
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: transfersWatchCmd,
+			FlagConfig: append([]utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID(s). Uses environment variable if blank, supports comma-separated values for multiple IDs",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TransferIdFlag,
+					Shorthand:    "i",
+					Usage:        "Comma separated IDs of the transfers to watch. Watches pending transfers of the portfolio if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.TypeFlag,
+					Shorthand:    "y",
+					Usage:        "Only watch transfers of this type, e.g. WITHDRAW",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.FromFlag,
+					Usage:        "Look for pending transfers created since this time (RFC3339 or YYYY-MM-DD). Defaults to 24 hours ago",
+					DefaultValue: "",
+					Required:     false,
+				},
+			}, transferWatchFlagConfigs()...),
+		},
+	}
+
+	utils.RegisterCommandConfigs(transfersCmd, cmdConfigs)
+}
@@ -0,0 +1,457 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+const (
+	tuiRecentFills = 50
+	tuiHelp        = "[yellow]Tab[-] focus  [yellow]n[-] new order  [yellow]m[-] modify  [yellow]c[-] cancel  [yellow]p[-] portfolio  [yellow]r[-] refresh  [yellow]q[-] quit"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:         "tui",
+	Short:       "Open a full-screen terminal UI with positions, balances, orders, fills and quotes.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
+		if err != nil {
+			return fmt.Errorf("cannot initialize from environment: %w", err)
+		}
+
+		environment, err := utils.ResolveEnvironment(cmd)
+		if err != nil {
+			return err
+		}
+
+		refresh, _ := cmd.Flags().GetInt(utils.RefreshFlag)
+		if refresh <= 0 {
+			return fmt.Errorf("invalid refresh interval %d: must be positive", refresh)
+		}
+
+		var watchlist []string
+		for _, id := range strings.Split(utils.GetFlagStringValue(cmd, utils.WatchlistFlag), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				watchlist = append(watchlist, id)
+			}
+		}
+
+		t := newTui(cmd, client, environment, portfolioId, watchlist, time.Duration(refresh)*time.Second)
+		return t.run()
+	},
+}
+
+// tui holds the panes of the terminal UI and the data last shown in them.
+// Data is fetched off the UI goroutine and applied with QueueUpdateDraw.
+type tui struct {
+	cmd         *cobra.Command
+	client      *intx.Client
+	environment *utils.Environment
+	watchlist   []string
+	refresh     time.Duration
+
+	app       *tview.Application
+	pages     *tview.Pages
+	header    *tview.TextView
+	status    *tview.TextView
+	positions *tview.Table
+	balances  *tview.Table
+	orders    *tview.Table
+	fills     *tview.Table
+	quotes    *tview.Table
+	focusable []tview.Primitive
+
+	mu          sync.Mutex
+	portfolioId string
+	openOrders  []intx.Order
+	refreshNow  chan struct{}
+}
+
+func newTui(cmd *cobra.Command, client *intx.Client, environment *utils.Environment, portfolioId string, watchlist []string, refresh time.Duration) *tui {
+	t := &tui{
+		cmd:         cmd,
+		client:      client,
+		environment: environment,
+		watchlist:   watchlist,
+		refresh:     refresh,
+		portfolioId: portfolioId,
+		app:         tview.NewApplication(),
+		pages:       tview.NewPages(),
+		header:      tview.NewTextView().SetDynamicColors(true),
+		status:      tview.NewTextView().SetDynamicColors(true),
+		positions:   newTuiTable("Positions", false),
+		balances:    newTuiTable("Balances", false),
+		orders:      newTuiTable("Open orders", true),
+		fills:       newTuiTable("Recent fills", false),
+		quotes:      newTuiTable("Watchlist", false),
+		refreshNow:  make(chan struct{}, 1),
+	}
+	t.focusable = []tview.Primitive{t.orders, t.positions, t.balances, t.fills, t.quotes}
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.header, 1, 0, false).
+		AddItem(tview.NewFlex().
+			AddItem(t.positions, 0, 3, false).
+			AddItem(t.balances, 0, 2, false), 0, 1, false).
+		AddItem(t.orders, 0, 1, true).
+		AddItem(tview.NewFlex().
+			AddItem(t.fills, 0, 3, false).
+			AddItem(t.quotes, 0, 2, false), 0, 1, false).
+		AddItem(t.status, 1, 0, false)
+
+	t.pages.AddPage("main", layout, true, true)
+	t.app.SetRoot(t.pages, true).SetFocus(t.orders).SetInputCapture(t.handleKey)
+	t.updateHeader()
+	t.setStatus(tuiHelp)
+	return t
+}
+
+func newTuiTable(title string, selectable bool) *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0).SetSelectable(selectable, false)
+	table.SetBorder(true).SetTitle(" " + title + " ")
+	return table
+}
+
+func (t *tui) run() error {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go t.refreshLoop(stop)
+	return t.app.Run()
+}
+
+func (t *tui) refreshLoop(stop chan struct{}) {
+	ticker := time.NewTicker(t.refresh)
+	defer ticker.Stop()
+
+	for {
+		t.refreshAll()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-t.refreshNow:
+		}
+	}
+}
+
+func (t *tui) requestRefresh() {
+	select {
+	case t.refreshNow <- struct{}{}:
+	default:
+	}
+}
+
+func (t *tui) currentPortfolioId() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.portfolioId
+}
+
+func (t *tui) refreshAll() {
+	portfolioId := t.currentPortfolioId()
+
+	var wg sync.WaitGroup
+	var state *utils.PortfolioState
+	var fills []intx.Fill
+	var stateErr, fillsErr error
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		state, stateErr = utils.FetchPortfolioState(t.client, &intx.Portfolio{PortfolioId: portfolioId})
+	}()
+	go func() {
+		defer wg.Done()
+		fills, fillsErr = listRecentFills(t.client, portfolioId)
+	}()
+	wg.Wait()
+
+	watchlist := t.watchlist
+	if len(watchlist) == 0 && state != nil {
+		for _, p := range state.Positions {
+			watchlist = append(watchlist, p.Symbol)
+		}
+	}
+	quotes, quoteErrs := fetchQuotes(t.client, watchlist)
+
+	t.app.QueueUpdateDraw(func() {
+		if portfolioId != t.currentPortfolioId() {
+			return
+		}
+
+		var problems []string
+		if stateErr != nil {
+			problems = append(problems, stateErr.Error())
+		} else {
+			t.showPositions(state.Positions)
+			t.showBalances(state.Balances)
+			t.showOrders(state.OpenOrders)
+		}
+		if fillsErr != nil {
+			problems = append(problems, fillsErr.Error())
+		} else {
+			t.showFills(fills)
+		}
+		t.showQuotes(watchlist, quotes, quoteErrs)
+
+		t.updateHeader()
+		if len(problems) > 0 {
+			t.setStatus("[red]" + tview.Escape(strings.Join(problems, "; ")) + "[-]")
+		}
+	})
+}
+
+func listRecentFills(client *intx.Client, portfolioId string) ([]intx.Fill, error) {
+	ctx, cancel := utils.GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.ListFillsByPortfolios(ctx, &intx.ListFillsByPortfoliosRequest{
+		PortfolioIds: portfolioId,
+		Pagination:   &intx.PaginationParams{ResultLimit: tuiRecentFills},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list fills: %w", err)
+	}
+
+	fills := response.Results
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].EventTime > fills[j].EventTime })
+	return fills, nil
+}
+
+func fetchQuotes(client *intx.Client, instrumentIds []string) ([]*intx.Quote, []error) {
+	quotes := make([]*intx.Quote, len(instrumentIds))
+	errs := make([]error, len(instrumentIds))
+
+	var wg sync.WaitGroup
+	for i, id := range instrumentIds {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			quotes[i], errs[i] = utils.GetQuote(client, id)
+		}(i, id)
+	}
+	wg.Wait()
+	return quotes, errs
+}
+
+func (t *tui) updateHeader() {
+	environment := t.environment.Name
+	if t.environment.IsProduction() {
+		environment = "[red::b]PRODUCTION[-::-]"
+	}
+	profile := t.environment.Profile
+	if profile == "" {
+		profile = "default"
+	}
+	t.header.SetText(fmt.Sprintf(" intxctl  env: %s  profile: %s  portfolio: %s  updated: %s",
+		environment, tview.Escape(profile), tview.Escape(t.currentPortfolioId()), time.Now().Format(time.TimeOnly)))
+}
+
+func (t *tui) setStatus(text string) {
+	t.status.SetText(" " + text)
+}
+
+// setStatusAsync may be called from any goroutine.
+func (t *tui) setStatusAsync(text string) {
+	t.app.QueueUpdateDraw(func() { t.setStatus(text) })
+}
+
+func setTableRows(table *tview.Table, headers []string, rows [][]string) {
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	for c, h := range headers {
+		table.SetCell(0, c, tview.NewTableCell(h).SetTextColor(tcell.ColorYellow).SetSelectable(false).SetExpansion(1))
+	}
+	for r, values := range rows {
+		for c, v := range values {
+			table.SetCell(r+1, c, tview.NewTableCell(tview.Escape(v)).SetExpansion(1))
+		}
+	}
+
+	if row < 1 {
+		row = 1
+	}
+	if row > len(rows) {
+		row = len(rows)
+	}
+	table.Select(row, 0)
+}
+
+func (t *tui) showPositions(positions []intx.Position) {
+	var rows [][]string
+	for _, p := range positions {
+		rows = append(rows, []string{p.Symbol, p.NetSize, p.Vwap, p.MarkPrice, p.UnrealizedPnl, p.ImContribution})
+	}
+	setTableRows(t.positions, []string{"SYMBOL", "NET_SIZE", "VWAP", "MARK", "UPNL", "IM"}, rows)
+}
+
+func (t *tui) showBalances(balances []intx.Balance) {
+	var rows [][]string
+	for _, b := range balances {
+		rows = append(rows, []string{b.AssetName, b.Quantity, b.Hold, b.CollateralValue, b.MaxWithdrawAmount})
+	}
+	setTableRows(t.balances, []string{"ASSET", "QUANTITY", "HOLD", "COLLATERAL", "MAX_WITHDRAW"}, rows)
+}
+
+func (t *tui) showOrders(orders []intx.Order) {
+	t.mu.Lock()
+	t.openOrders = orders
+	t.mu.Unlock()
+
+	var rows [][]string
+	for _, o := range orders {
+		rows = append(rows, []string{o.OrderId, o.Symbol, o.Side, o.Type, o.Size, o.Price, o.StopPrice, o.LeavesQty, o.Tif})
+	}
+	setTableRows(t.orders, []string{"ORDER_ID", "SYMBOL", "SIDE", "TYPE", "SIZE", "PRICE", "STOP", "LEAVES", "TIF"}, rows)
+}
+
+func (t *tui) showFills(fills []intx.Fill) {
+	var rows [][]string
+	for _, f := range fills {
+		rows = append(rows, []string{f.EventTime, f.Symbol, f.Side, f.FillQty, f.FillPrice, f.Fee + " " + f.FeeAsset})
+	}
+	setTableRows(t.fills, []string{"TIME", "SYMBOL", "SIDE", "QTY", "PRICE", "FEE"}, rows)
+}
+
+func (t *tui) showQuotes(instrumentIds []string, quotes []*intx.Quote, errs []error) {
+	var rows [][]string
+	for i, id := range instrumentIds {
+		if errs[i] != nil {
+			rows = append(rows, []string{id, "error", "", "", ""})
+			continue
+		}
+		q := quotes[i]
+		rows = append(rows, []string{id, q.BestBidPrice, q.BestAskPrice, q.TradePrice, q.MarkPrice})
+	}
+	setTableRows(t.quotes, []string{"INSTRUMENT", "BID", "ASK", "LAST", "MARK"}, rows)
+}
+
+// selectedOrder returns the open order highlighted in the orders pane.
+func (t *tui) selectedOrder() (intx.Order, bool) {
+	row, _ := t.orders.GetSelection()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if row < 1 || row > len(t.openOrders) {
+		return intx.Order{}, false
+	}
+	return t.openOrders[row-1], true
+}
+
+func (t *tui) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if name, _ := t.pages.GetFrontPage(); name != "main" {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab:
+		t.cycleFocus(event.Key() == tcell.KeyTab)
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch event.Rune() {
+	case 'q':
+		t.app.Stop()
+	case 'r':
+		t.setStatus("Refreshing...")
+		t.requestRefresh()
+	case 'n':
+		t.showNewOrderForm()
+	case 'm':
+		if order, ok := t.selectedOrder(); ok {
+			t.showModifyOrderForm(order)
+		} else {
+			t.setStatus("[red]No order selected[-]")
+		}
+	case 'c':
+		if order, ok := t.selectedOrder(); ok {
+			t.confirmCancelOrder(order)
+		} else {
+			t.setStatus("[red]No order selected[-]")
+		}
+	case 'p':
+		t.showPortfolioPicker()
+	default:
+		return event
+	}
+	return nil
+}
+
+func (t *tui) cycleFocus(forward bool) {
+	current := t.app.GetFocus()
+	for i, p := range t.focusable {
+		if p != current {
+			continue
+		}
+		next := i + 1
+		if !forward {
+			next = i - 1 + len(t.focusable)
+		}
+		t.app.SetFocus(t.focusable[next%len(t.focusable)])
+		return
+	}
+	t.app.SetFocus(t.focusable[0])
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: tuiCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.PortfolioIdFlag,
+					Shorthand:    "p",
+					Usage:        "Portfolio ID to open with. Uses environment variable if blank",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.WatchlistFlag,
+					Shorthand:    "w",
+					Usage:        "Comma separated instruments to quote. Defaults to the instruments of open positions",
+					DefaultValue: "",
+					Required:     false,
+				},
+				{
+					FlagName:     utils.RefreshFlag,
+					Shorthand:    "r",
+					Usage:        "Refresh interval in seconds",
+					DefaultValue: 5,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(rootCmd, cmdConfigs)
+}
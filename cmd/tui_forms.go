@@ -0,0 +1,238 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/google/uuid"
+	"github.com/rivo/tview"
+)
+
+const tuiDialog = "dialog"
+
+var (
+	tuiSides      = []string{utils.SideBuy, utils.SideSell}
+	tuiOrderTypes = []string{utils.OrderTypeLimit, utils.OrderTypeMarket, utils.OrderTypeStop, utils.OrderTypeStopLimit}
+	tuiTifs       = []string{"GTC", "IOC", "FOK", "GTT"}
+)
+
+// showDialog centers p over the main page. Only one dialog is open at a time.
+func (t *tui) showDialog(p tview.Primitive, width, height int) {
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	t.pages.RemovePage(tuiDialog)
+	t.pages.AddPage(tuiDialog, centered, true, true)
+	t.app.SetFocus(p)
+}
+
+func (t *tui) closeDialog() {
+	t.pages.RemovePage(tuiDialog)
+	t.app.SetFocus(t.orders)
+}
+
+func formText(form *tview.Form, label string) string {
+	return form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+}
+
+func formOption(form *tview.Form, label string) string {
+	_, option := form.GetFormItemByLabel(label).(*tview.DropDown).GetCurrentOption()
+	return option
+}
+
+func (t *tui) showNewOrderForm() {
+	instrument := ""
+	if row, _ := t.quotes.GetSelection(); row > 0 {
+		instrument = t.quotes.GetCell(row, 0).Text
+	}
+
+	form := tview.NewForm().
+		AddInputField("Instrument", instrument, 20, nil, nil).
+		AddDropDown("Side", tuiSides, 0, nil).
+		AddDropDown("Type", tuiOrderTypes, 0, nil).
+		AddInputField("Size", "", 20, nil, nil).
+		AddInputField("Limit price", "", 20, nil, nil).
+		AddInputField("Stop price", "", 20, nil, nil).
+		AddDropDown("TIF", tuiTifs, 0, nil).
+		AddCheckbox("Post only", false, nil)
+
+	form.AddButton("Submit", func() {
+		postOnly := form.GetFormItemByLabel("Post only").(*tview.Checkbox).IsChecked()
+		request := &intx.CreateOrderRequest{
+			ClientOrderId: uuid.New().String(),
+			PortfolioId:   t.currentPortfolioId(),
+			InstrumentId:  formText(form, "Instrument"),
+			Side:          formOption(form, "Side"),
+			Size:          formText(form, "Size"),
+			Tif:           formOption(form, "TIF"),
+			Type:          formOption(form, "Type"),
+			Price:         formText(form, "Limit price"),
+			StopPrice:     utils.StringPtr(formText(form, "Stop price")),
+			PostOnly:      &postOnly,
+		}
+
+		if err := utils.ValidateCreateOrderRequest(request); err != nil {
+			t.setStatus("[red]Invalid order: " + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+
+		t.closeDialog()
+		t.setStatus("Submitting order...")
+		go func() {
+			ctx, cancel := utils.GetContextWithTimeout()
+			defer cancel()
+
+			response, err := t.client.CreateOrder(ctx, request)
+			utils.RecordAuditAction(t.cmd, t.cmd.CommandPath()+" create-order", request, response, err)
+			if err != nil {
+				t.setStatusAsync("[red]Cannot create order: " + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			t.setStatusAsync(fmt.Sprintf("[green]Order %s submitted[-]", response.Order.OrderId))
+			t.requestRefresh()
+		}()
+	})
+	form.AddButton("Close", t.closeDialog)
+	form.SetCancelFunc(t.closeDialog)
+	form.SetBorder(true).SetTitle(" New order ")
+
+	t.showDialog(form, 50, 21)
+}
+
+func (t *tui) showModifyOrderForm(order intx.Order) {
+	form := tview.NewForm().
+		AddInputField("Size", order.Size, 20, nil, nil).
+		AddInputField("Limit price", order.Price, 20, nil, nil).
+		AddInputField("Stop price", order.StopPrice, 20, nil, nil)
+
+	form.AddButton("Submit", func() {
+		request := &intx.ModifyOrderRequest{
+			OrderId:       order.OrderId,
+			PortfolioId:   t.currentPortfolioId(),
+			ClientOrderId: uuid.New().String(),
+		}
+		if size := formText(form, "Size"); size != order.Size {
+			request.Size = size
+		}
+		if price := formText(form, "Limit price"); price != order.Price {
+			request.Price = price
+		}
+		if stopPrice := formText(form, "Stop price"); stopPrice != order.StopPrice {
+			request.StopPrice = stopPrice
+		}
+
+		if err := utils.ValidateModifyOrderRequest(order, request); err != nil {
+			t.setStatus("[red]Invalid modification: " + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+
+		t.closeDialog()
+		t.setStatus("Modifying order " + order.OrderId + "...")
+		go func() {
+			ctx, cancel := utils.GetContextWithTimeout()
+			defer cancel()
+
+			response, err := t.client.ModifyOrder(ctx, request)
+			utils.RecordAuditAction(t.cmd, t.cmd.CommandPath()+" modify-order", request, response, err)
+			if err != nil {
+				t.setStatusAsync("[red]Cannot modify order: " + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			t.setStatusAsync("[green]Order " + order.OrderId + " modified[-]")
+			t.requestRefresh()
+		}()
+	})
+	form.AddButton("Close", t.closeDialog)
+	form.SetCancelFunc(t.closeDialog)
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Modify %s %s %s ", order.Side, order.Symbol, order.OrderId))
+
+	t.showDialog(form, 60, 11)
+}
+
+func (t *tui) confirmCancelOrder(order intx.Order) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Cancel %s %s %s @ %s?\n%s", order.Side, order.LeavesQty, order.Symbol, order.Price, order.OrderId)).
+		AddButtons([]string{"Cancel order", "Keep"}).
+		SetDoneFunc(func(_ int, label string) {
+			t.pages.RemovePage(tuiDialog)
+			t.app.SetFocus(t.orders)
+			if label != "Cancel order" {
+				return
+			}
+
+			t.setStatus("Cancelling order " + order.OrderId + "...")
+			go func() {
+				ctx, cancel := utils.GetContextWithTimeout()
+				defer cancel()
+
+				request := &intx.CancelOrderRequest{PortfolioId: t.currentPortfolioId(), OrderId: order.OrderId}
+				response, err := t.client.CancelOrder(ctx, request)
+				utils.RecordAuditAction(t.cmd, t.cmd.CommandPath()+" cancel-order", request, response, err)
+				if err != nil {
+					t.setStatusAsync("[red]Cannot cancel order: " + tview.Escape(err.Error()) + "[-]")
+					return
+				}
+				t.setStatusAsync("[green]Order " + order.OrderId + " cancelled[-]")
+				t.requestRefresh()
+			}()
+		})
+
+	t.pages.RemovePage(tuiDialog)
+	t.pages.AddPage(tuiDialog, modal, true, true)
+	t.app.SetFocus(modal)
+}
+
+func (t *tui) showPortfolioPicker() {
+	t.setStatus("Loading portfolios...")
+	go func() {
+		portfolios, err := utils.ListPortfolios(t.client)
+		t.app.QueueUpdateDraw(func() {
+			if err != nil {
+				t.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			t.setStatus(tuiHelp)
+
+			list := tview.NewList().ShowSecondaryText(false)
+			for _, p := range portfolios {
+				portfolioId := p.PortfolioId
+				list.AddItem(fmt.Sprintf("%s  %s", tview.Escape(p.Name), portfolioId), "", 0, func() {
+					t.mu.Lock()
+					t.portfolioId = portfolioId
+					t.openOrders = nil
+					t.mu.Unlock()
+
+					t.closeDialog()
+					t.updateHeader()
+					t.setStatus("Switched to portfolio " + portfolioId)
+					t.requestRefresh()
+				})
+			}
+			list.SetDoneFunc(t.closeDialog)
+			list.SetBorder(true).SetTitle(" Portfolio ")
+
+			t.showDialog(list, 70, len(portfolios)+2)
+		})
+	}()
+}
@@ -24,8 +24,9 @@ import (
 )
 
 var updatePortfolioCmd = &cobra.Command{
-	Use:   "update-portfolio",
-	Short: "update a portfolio name.",
+	Use:         "update-portfolio",
+	Short:       "update a portfolio name.",
+	Annotations: map[string]string{utils.MutatingAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, portfolioId, err := utils.InitClientAndPortfolioId(cmd, true)
 		if err != nil {
@@ -41,6 +42,7 @@ var updatePortfolioCmd = &cobra.Command{
 		}
 
 		response, err := client.UpdatePortfolio(ctx, request)
+		utils.RecordAudit(cmd, request, response, err)
 		if err != nil {
 			return fmt.Errorf("cannot update portfolio: %w", err)
 		}
@@ -0,0 +1,85 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"sort"
+	"time"
+)
+
+type withdrawalLimitStatus struct {
+	AssetId        string `json:"assetId"`
+	DailyLimit     string `json:"dailyLimit"`
+	WithdrawnToday string `json:"withdrawnToday"`
+	Remaining      string `json:"remaining"`
+}
+
+var withdrawalLimitsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List daily withdrawal limits, today's usage and network fee estimates.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limits, err := utils.LoadWithdrawalLimits()
+		if err != nil {
+			return fmt.Errorf("cannot load withdrawal limits: %w", err)
+		}
+
+		now := time.Now()
+		statuses := []withdrawalLimitStatus{}
+		for assetId, limitStr := range limits.DailyLimits {
+			limit := utils.ParseAmountOrZero(limitStr)
+			used := limits.WithdrawnOn(assetId, now)
+			statuses = append(statuses, withdrawalLimitStatus{
+				AssetId:        assetId,
+				DailyLimit:     limitStr,
+				WithdrawnToday: utils.FormatAmount(used),
+				Remaining:      utils.FormatAmount(max(limit-used, 0)),
+			})
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].AssetId < statuses[j].AssetId })
+
+		response := struct {
+			Limits              []withdrawalLimitStatus `json:"limits"`
+			NetworkFeeEstimates map[string]string       `json:"localNetworkFeeEstimates"`
+		}{
+			Limits:              statuses,
+			NetworkFeeEstimates: limits.NetworkFeeEstimates,
+		}
+
+		return utils.PrintJsonResponse(cmd, response)
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: withdrawalLimitsListCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.FormatFlag,
+					Shorthand:    "z",
+					Usage:        "Pass true for formatted JSON. Default is false",
+					DefaultValue: false,
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(withdrawalLimitsCmd, cmdConfigs)
+}
@@ -0,0 +1,122 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/coinbase-samples/intx-cli/utils"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var withdrawalLimitsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the daily withdrawal limit for an asset. An empty amount removes the limit.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assetId := strings.ToUpper(utils.GetFlagStringValue(cmd, utils.AssetIdFlag))
+		amount := utils.GetFlagStringValue(cmd, utils.AmountFlag)
+
+		if _, err := utils.ParseAmount(amount); err != nil {
+			return err
+		}
+
+		err := utils.UpdateWithdrawalLimits(func(limits *utils.WithdrawalLimits) error {
+			if amount == "" {
+				delete(limits.DailyLimits, assetId)
+			} else {
+				limits.DailyLimits[assetId] = amount
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot save withdrawal limits: %w", err)
+		}
+
+		return nil
+	},
+}
+
+var withdrawalLimitsSetNetworkFeeCmd = &cobra.Command{
+	Use:   "set-network-fee",
+	Short: "Set the local network fee estimate for a network. An empty amount removes the estimate.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkArnId := utils.GetFlagStringValue(cmd, utils.NetworkArnIdFlag)
+		amount := utils.GetFlagStringValue(cmd, utils.AmountFlag)
+
+		if _, err := utils.ParseAmount(amount); err != nil {
+			return err
+		}
+
+		err := utils.UpdateWithdrawalLimits(func(limits *utils.WithdrawalLimits) error {
+			if amount == "" {
+				delete(limits.NetworkFeeEstimates, networkArnId)
+			} else {
+				limits.NetworkFeeEstimates[networkArnId] = amount
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot save network fee estimate: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	cmdConfigs := []utils.CommandConfig{
+		{
+			Command: withdrawalLimitsSetCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.AssetIdFlag,
+					Shorthand:    "i",
+					Usage:        "ID of the asset, e.g. USDC (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.AmountFlag,
+					Shorthand:    "a",
+					Usage:        "Maximum amount withdrawn per UTC day",
+					DefaultValue: "",
+					Required:     false,
+				},
+			},
+		},
+		{
+			Command: withdrawalLimitsSetNetworkFeeCmd,
+			FlagConfig: []utils.FlagConfig{
+				{
+					FlagName:     utils.NetworkArnIdFlag,
+					Shorthand:    "n",
+					Usage:        "Network Arn Id (Required)",
+					DefaultValue: "",
+					Required:     true,
+				},
+				{
+					FlagName:     utils.AmountFlag,
+					Shorthand:    "a",
+					Usage:        "Expected network fee in units of the withdrawn asset. Not checked against the exchange",
+					DefaultValue: "",
+					Required:     false,
+				},
+			},
+		},
+	}
+
+	utils.RegisterCommandConfigs(withdrawalLimitsCmd, cmdConfigs)
+}
@@ -0,0 +1,228 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	addressBookFileName = "address_book.json"
+
+	AddressBookTypeCrypto       = "crypto"
+	AddressBookTypeCounterparty = "counterparty"
+)
+
+type AddressBookEntry struct {
+	Name           string `json:"name"`
+	Label          string `json:"label,omitempty"`
+	Type           string `json:"type"`
+	AssetId        string `json:"assetId,omitempty"`
+	NetworkArnId   string `json:"networkArnId,omitempty"`
+	Address        string `json:"address,omitempty"`
+	Memo           string `json:"memo,omitempty"`
+	CounterpartyId string `json:"counterpartyId,omitempty"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type AddressBook struct {
+	EnforceAllowlist bool                         `json:"enforceAllowlist"`
+	Entries          map[string]*AddressBookEntry `json:"entries"`
+}
+
+func GetAddressBookPath() (string, error) {
+	return GetCliHomePath(addressBookFileName)
+}
+
+func LoadAddressBook() (*AddressBook, error) {
+	path, err := GetAddressBookPath()
+	if err != nil {
+		return nil, err
+	}
+
+	book := &AddressBook{}
+	if err := ReadJsonFile(path, book); err != nil {
+		return nil, err
+	}
+	if book.Entries == nil {
+		book.Entries = map[string]*AddressBookEntry{}
+	}
+	return book, nil
+}
+
+// UpdateAddressBook loads the address book, applies update and saves it.
+func UpdateAddressBook(update func(book *AddressBook) error) error {
+	path, err := GetAddressBookPath()
+	if err != nil {
+		return err
+	}
+
+	book := &AddressBook{}
+	return UpdateJsonFile(path, book, func() error {
+		if book.Entries == nil {
+			book.Entries = map[string]*AddressBookEntry{}
+		}
+		return update(book)
+	})
+}
+
+func (b *AddressBook) SortedEntries() []*AddressBookEntry {
+	entries := make([]*AddressBookEntry, 0, len(b.Entries))
+	for _, e := range b.Entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Lookup returns the named entry, checking that it is of the expected type.
+func (b *AddressBook) Lookup(name, entryType string) (*AddressBookEntry, error) {
+	entry, ok := b.Entries[name]
+	if !ok {
+		return nil, fmt.Errorf("address book entry %s not found", name)
+	}
+	if entry.Type != entryType {
+		return nil, fmt.Errorf("address book entry %s is a %s destination, not %s", name, entry.Type, entryType)
+	}
+	return entry, nil
+}
+
+// FindCryptoAddress returns the entry matching a raw withdrawal destination.
+func (b *AddressBook) FindCryptoAddress(assetId, networkArnId, address string) *AddressBookEntry {
+	for _, e := range b.SortedEntries() {
+		if e.Type == AddressBookTypeCrypto &&
+			strings.EqualFold(e.AssetId, assetId) &&
+			e.NetworkArnId == networkArnId &&
+			e.Address == address {
+			return e
+		}
+	}
+	return nil
+}
+
+func (b *AddressBook) FindCounterparty(counterpartyId string) *AddressBookEntry {
+	for _, e := range b.SortedEntries() {
+		if e.Type == AddressBookTypeCounterparty && e.CounterpartyId == counterpartyId {
+			return e
+		}
+	}
+	return nil
+}
+
+func (e *AddressBookEntry) Validate() error {
+	if e.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch e.Type {
+	case AddressBookTypeCrypto:
+		if e.AssetId == "" || e.NetworkArnId == "" || e.Address == "" {
+			return errors.New("asset ID, network ARN ID and address are required for crypto destinations")
+		}
+		if e.Memo != "" {
+			return errors.New("destinations with a memo or tag are not supported: withdrawal requests cannot include one")
+		}
+	case AddressBookTypeCounterparty:
+		if e.Address != "" || e.NetworkArnId != "" || e.Memo != "" {
+			return errors.New("address, network ARN ID and memo cannot be set on counterparty destinations")
+		}
+	default:
+		return fmt.Errorf("invalid destination type %s", e.Type)
+	}
+	return nil
+}
+
+// ResolveCryptoDestination returns the withdrawal destination named by the
+// to-name flag, or the one given by the asset, network and address flags.
+// Unlisted destinations are refused when the allowlist is enforced.
+func ResolveCryptoDestination(cmd *cobra.Command) (*AddressBookEntry, error) {
+	book, err := LoadAddressBook()
+	if err != nil {
+		return nil, err
+	}
+
+	assetId := GetFlagStringValue(cmd, AssetIdFlag)
+	networkArnId := GetFlagStringValue(cmd, NetworkArnIdFlag)
+	address := GetFlagStringValue(cmd, AddressFlag)
+
+	if name := GetFlagStringValue(cmd, ToNameFlag); name != "" {
+		if networkArnId != "" || address != "" {
+			return nil, fmt.Errorf("%s cannot be combined with %s or %s", ToNameFlag, NetworkArnIdFlag, AddressFlag)
+		}
+		entry, err := book.Lookup(name, AddressBookTypeCrypto)
+		if err != nil {
+			return nil, err
+		}
+		if assetId != "" && !strings.EqualFold(assetId, entry.AssetId) {
+			return nil, fmt.Errorf("address book entry %s is for %s, not %s", name, entry.AssetId, assetId)
+		}
+		return entry, nil
+	}
+
+	if assetId == "" || networkArnId == "" || address == "" {
+		return nil, fmt.Errorf("either %s or all of %s, %s and %s are required", ToNameFlag, AssetIdFlag, NetworkArnIdFlag, AddressFlag)
+	}
+
+	if entry := book.FindCryptoAddress(assetId, networkArnId, address); entry != nil {
+		return entry, nil
+	}
+	if book.EnforceAllowlist {
+		return nil, fmt.Errorf("address %s on %s is not in the address book and the allowlist is enforced", address, networkArnId)
+	}
+
+	return &AddressBookEntry{
+		Type:         AddressBookTypeCrypto,
+		AssetId:      assetId,
+		NetworkArnId: networkArnId,
+		Address:      address,
+	}, nil
+}
+
+// ResolveCounterpartyDestination is the counterparty ID equivalent of
+// ResolveCryptoDestination.
+func ResolveCounterpartyDestination(cmd *cobra.Command) (*AddressBookEntry, error) {
+	book, err := LoadAddressBook()
+	if err != nil {
+		return nil, err
+	}
+
+	counterpartyId := GetFlagStringValue(cmd, CounterpartyIdFlag)
+
+	if name := GetFlagStringValue(cmd, ToNameFlag); name != "" {
+		if counterpartyId != "" {
+			return nil, fmt.Errorf("%s cannot be combined with %s", ToNameFlag, CounterpartyIdFlag)
+		}
+		return book.Lookup(name, AddressBookTypeCounterparty)
+	}
+
+	if counterpartyId == "" {
+		return nil, fmt.Errorf("either %s or %s is required", ToNameFlag, CounterpartyIdFlag)
+	}
+
+	if entry := book.FindCounterparty(counterpartyId); entry != nil {
+		return entry, nil
+	}
+	if book.EnforceAllowlist {
+		return nil, fmt.Errorf("counterparty %s is not in the address book and the allowlist is enforced", counterpartyId)
+	}
+
+	return &AddressBookEntry{Type: AddressBookTypeCounterparty, CounterpartyId: counterpartyId}, nil
+}
@@ -0,0 +1,295 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	AddressFormatEvm            = "evm"
+	AddressFormatBitcoin        = "bitcoin"
+	AddressFormatBitcoinTestnet = "bitcoin-testnet"
+	AddressFormatSolana         = "solana"
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	bech32Charset  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+var evmNetworks = []string{
+	"ethereum", "base", "arbitrum", "optimism", "polygon", "avalanche", "avax", "bsc", "binance",
+}
+
+var bitcoinTestNetworks = []string{"testnet", "signet", "regtest"}
+
+// AddressFormatForNetwork maps a network name or ARN ID to the address format
+// it uses, or returns an empty string when the format is not known.
+func AddressFormatForNetwork(network string) string {
+	network = strings.ToLower(network)
+	switch {
+	case strings.Contains(network, "bitcoin") && !strings.Contains(network, "cash"):
+		for _, name := range bitcoinTestNetworks {
+			if strings.Contains(network, name) {
+				return AddressFormatBitcoinTestnet
+			}
+		}
+		return AddressFormatBitcoin
+	case strings.Contains(network, "solana"):
+		return AddressFormatSolana
+	}
+	for _, name := range evmNetworks {
+		if strings.Contains(network, name) {
+			return AddressFormatEvm
+		}
+	}
+	return ""
+}
+
+func ValidateAddress(format, address string) error {
+	switch format {
+	case AddressFormatEvm:
+		return ValidateEvmAddress(address)
+	case AddressFormatBitcoin:
+		return ValidateBitcoinAddress(address, false)
+	case AddressFormatBitcoinTestnet:
+		return ValidateBitcoinAddress(address, true)
+	case AddressFormatSolana:
+		return ValidateSolanaAddress(address)
+	default:
+		return fmt.Errorf("unknown address format %s", format)
+	}
+}
+
+// ValidateEvmAddress checks the length and hex encoding of an EVM address and,
+// when it is mixed case, its EIP-55 checksum.
+func ValidateEvmAddress(address string) error {
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+		return errors.New("EVM address must be 0x followed by 40 hex characters")
+	}
+
+	hexPart := address[2:]
+	if _, err := hex.DecodeString(hexPart); err != nil {
+		return errors.New("EVM address contains non-hex characters")
+	}
+
+	if hexPart == strings.ToLower(hexPart) || hexPart == strings.ToUpper(hexPart) {
+		return nil
+	}
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(strings.ToLower(hexPart)))
+	digest := hash.Sum(nil)
+
+	for i, c := range hexPart {
+		if c >= '0' && c <= '9' {
+			continue
+		}
+		nibble := digest[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		upper := c >= 'A' && c <= 'F'
+		if upper != (nibble&0x0f >= 8) {
+			return errors.New("EVM address has an invalid EIP-55 checksum")
+		}
+	}
+	return nil
+}
+
+// ValidateBitcoinAddress accepts bech32/bech32m segwit addresses and
+// base58check P2PKH/P2SH addresses of mainnet or, when testnet is set, of
+// testnet, signet and regtest. Addresses of the other kind are rejected.
+func ValidateBitcoinAddress(address string, testnet bool) error {
+	hrps, versions := []string{"bc"}, []byte{0x00, 0x05}
+	if testnet {
+		hrps, versions = []string{"tb", "bcrt"}, []byte{0x6f, 0xc4}
+	}
+
+	lower := strings.ToLower(address)
+	for _, hrp := range []string{"bc1", "tb1", "bcrt1"} {
+		if strings.HasPrefix(lower, hrp) {
+			return validateSegwitAddress(address, hrps)
+		}
+	}
+
+	decoded, err := decodeBase58(address)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != 25 {
+		return errors.New("base58 Bitcoin address must decode to 25 bytes")
+	}
+
+	switch decoded[0] {
+	case 0x00, 0x05, 0x6f, 0xc4:
+		if !bytes.Contains(versions, decoded[:1]) {
+			return fmt.Errorf("Bitcoin address version 0x%02x belongs to another network", decoded[0])
+		}
+	default:
+		return fmt.Errorf("unknown Bitcoin address version 0x%02x", decoded[0])
+	}
+
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], decoded[21:]) {
+		return errors.New("Bitcoin address has an invalid base58 checksum")
+	}
+	return nil
+}
+
+func ValidateSolanaAddress(address string) error {
+	decoded, err := decodeBase58(address)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != 32 {
+		return fmt.Errorf("Solana address must decode to 32 bytes, got %d", len(decoded))
+	}
+	return nil
+}
+
+func validateSegwitAddress(address string, hrps []string) error {
+	if address != strings.ToLower(address) && address != strings.ToUpper(address) {
+		return errors.New("bech32 address cannot be mixed case")
+	}
+	address = strings.ToLower(address)
+
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || sep+7 > len(address) || len(address) > 90 {
+		return errors.New("bech32 address has an invalid length")
+	}
+
+	hrp := address[:sep]
+	if !slices.Contains(hrps, hrp) {
+		return fmt.Errorf("bech32 address prefix %s belongs to another network", hrp)
+	}
+	data := make([]byte, 0, len(address)-sep-1)
+	for _, c := range address[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return fmt.Errorf("bech32 address contains invalid character %q", c)
+		}
+		data = append(data, byte(v))
+	}
+
+	checksum := bech32Polymod(append(bech32HrpExpand(hrp), data...))
+	if checksum != bech32Const && checksum != bech32mConst {
+		return errors.New("Bitcoin address has an invalid bech32 checksum")
+	}
+
+	data = data[:len(data)-6]
+	if len(data) == 0 {
+		return errors.New("bech32 address is missing a witness version")
+	}
+
+	version := data[0]
+	if version > 16 {
+		return fmt.Errorf("invalid witness version %d", version)
+	}
+	if (version == 0) != (checksum == bech32Const) {
+		return errors.New("witness version does not match bech32 variant")
+	}
+
+	program, err := convertBits(data[1:], 5, 8)
+	if err != nil {
+		return err
+	}
+	if len(program) < 2 || len(program) > 40 || (version == 0 && len(program) != 20 && len(program) != 32) {
+		return fmt.Errorf("invalid witness program length %d", len(program))
+	}
+	return nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range hrp {
+		expanded = append(expanded, byte(c>>5))
+	}
+	expanded = append(expanded, 0)
+	for _, c := range hrp {
+		expanded = append(expanded, byte(c&31))
+	}
+	return expanded
+}
+
+func convertBits(data []byte, fromBits, toBits uint) ([]byte, error) {
+	var acc, bits uint
+	maxValue := uint(1)<<toBits - 1
+	var converted []byte
+	for _, v := range data {
+		acc = acc<<fromBits | uint(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			converted = append(converted, byte(acc>>bits&maxValue))
+		}
+	}
+	if bits >= fromBits || (acc<<(toBits-bits))&maxValue != 0 {
+		return nil, errors.New("invalid bech32 padding")
+	}
+	return converted, nil
+}
+
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty base58 string")
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range s {
+		v := strings.IndexRune(base58Alphabet, c)
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+
+	decoded := n.Bytes()
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == base58Alphabet[0] {
+		leadingZeros++
+	}
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
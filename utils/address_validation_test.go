@@ -0,0 +1,122 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import "testing"
+
+func TestValidateEvmAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		valid   bool
+	}{
+		// EIP-55 test vectors.
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", true},
+		{"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB", true},
+		{"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", true},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", true},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", false},
+		{"0xFb6916095ca1df60bB79Ce92cE3Ea74c37c5d359", false},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", false},
+		{"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed00", false},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateEvmAddress(tt.address); (err == nil) != tt.valid {
+			t.Errorf("ValidateEvmAddress(%q) = %v, want valid %t", tt.address, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateBitcoinAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		testnet bool
+		valid   bool
+	}{
+		// BIP-173 and BIP-350 test vectors.
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", false, true},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", false, true},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", false, true},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", true, true},
+		{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", true, true},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", false, false},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7", true, false},
+		{"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", false, false},
+		{"bc1zw508d6qejxtdg4y5r3zarvaryvqyzf3du", false, false},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0", false, false},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", false, false},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true, false},
+
+		// Base58check P2PKH and P2SH addresses.
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", false, true},
+		{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", false, true},
+		{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", true, true},
+		{"2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc", true, true},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", false, false},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfN0", false, false},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7Divf", false, false},
+		{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", false, false},
+		{"2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc", false, false},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", true, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateBitcoinAddress(tt.address, tt.testnet); (err == nil) != tt.valid {
+			t.Errorf("ValidateBitcoinAddress(%q, %t) = %v, want valid %t", tt.address, tt.testnet, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateSolanaAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		valid   bool
+	}{
+		{"11111111111111111111111111111111", true},
+		{"So11111111111111111111111111111111111111112", true},
+		{"So1111111111111111111111111111111111111111", false},
+		{"So11111111111111111111111111111111111111110", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateSolanaAddress(tt.address); (err == nil) != tt.valid {
+			t.Errorf("ValidateSolanaAddress(%q) = %v, want valid %t", tt.address, err, tt.valid)
+		}
+	}
+}
+
+func TestAddressFormatForNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		format  string
+	}{
+		{"bitcoin", AddressFormatBitcoin},
+		{"networks/bitcoin-mainnet", AddressFormatBitcoin},
+		{"bitcoin-testnet", AddressFormatBitcoinTestnet},
+		{"Bitcoin Signet", AddressFormatBitcoinTestnet},
+		{"bitcoincash", ""},
+		{"ethereum", AddressFormatEvm},
+		{"base", AddressFormatEvm},
+		{"solana", AddressFormatSolana},
+		{"cardano", ""},
+	}
+	for _, tt := range tests {
+		if format := AddressFormatForNetwork(tt.network); format != tt.format {
+			t.Errorf("AddressFormatForNetwork(%q) = %q, want %q", tt.network, format, tt.format)
+		}
+	}
+}
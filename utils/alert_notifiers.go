@@ -0,0 +1,214 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultSmtpPort = 587
+
+type AlertNotifier interface {
+	Notify(alert *Alert) error
+}
+
+// AlertDispatcher sends alerts to the channels named by their rule, or to
+// every channel when the rule names none. Without channels, alerts are
+// written to stdout.
+type AlertDispatcher struct {
+	names     []string
+	notifiers map[string]AlertNotifier
+}
+
+func NewAlertDispatcher(rules *AlertRules, out io.Writer) *AlertDispatcher {
+	d := &AlertDispatcher{notifiers: map[string]AlertNotifier{}}
+	for _, c := range rules.Channels {
+		d.names = append(d.names, c.Name)
+		d.notifiers[c.Name] = newAlertNotifier(c, out)
+	}
+	if len(d.names) == 0 {
+		d.names = []string{AlertChannelStdout}
+		d.notifiers[AlertChannelStdout] = &stdoutNotifier{out: out}
+	}
+	return d
+}
+
+// Dispatch delivers an alert and returns an error for each channel that
+// failed.
+func (d *AlertDispatcher) Dispatch(alert *Alert) []error {
+	names := alert.channels
+	if len(names) == 0 {
+		names = d.names
+	}
+
+	var errs []error
+	for _, name := range names {
+		if err := d.notifiers[name].Notify(alert); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// Channels returns the names of the configured channels.
+func (d *AlertDispatcher) Channels() []string {
+	return d.names
+}
+
+func newAlertNotifier(c *AlertChannel, out io.Writer) AlertNotifier {
+	switch c.Type {
+	case AlertChannelCommand:
+		return &commandNotifier{command: c.Command}
+	case AlertChannelSmtp:
+		return &smtpNotifier{settings: c.Smtp}
+	case AlertChannelWebhook:
+		return &webhookNotifier{url: c.Url, format: c.Format, headers: c.Headers}
+	}
+	return &stdoutNotifier{out: out}
+}
+
+type stdoutNotifier struct {
+	out io.Writer
+}
+
+func (n *stdoutNotifier) Notify(alert *Alert) error {
+	_, err := fmt.Fprintln(n.out, FormatAlert(alert))
+	return err
+}
+
+type commandNotifier struct {
+	command string
+}
+
+func (n *commandNotifier) Notify(alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "sh", "-c", n.command)
+	c.Stdin = bytes.NewReader(body)
+	c.Env = append(os.Environ(),
+		"INTX_ALERT_RULE="+alert.Rule,
+		"INTX_ALERT_TYPE="+alert.Type,
+		"INTX_ALERT_SUBJECT="+alert.Subject,
+		"INTX_ALERT_MESSAGE="+alert.Message,
+		"INTX_ALERT_TIME="+alert.Time.UTC().Format(time.RFC3339),
+	)
+	if output, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("command failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+type smtpNotifier struct {
+	settings *SmtpSettings
+}
+
+func (n *smtpNotifier) Notify(alert *Alert) error {
+	s := n.settings
+	port := s.Port
+	if port == 0 {
+		port = defaultSmtpPort
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, os.Getenv(s.PasswordEnv), s.Host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [intxctl] %s\r\n", alert.Rule)
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.Time.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(FormatAlert(alert) + "\r\n")
+
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, auth, s.From, s.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+	return nil
+}
+
+type webhookNotifier struct {
+	url     string
+	format  string
+	headers map[string]string
+}
+
+func (n *webhookNotifier) Notify(alert *Alert) error {
+	body, err := json.Marshal(webhookPayload(alert, n.format))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func webhookPayload(alert *Alert, format string) interface{} {
+	switch format {
+	case WebhookFormatSlack:
+		return map[string]string{"text": fmt.Sprintf("*%s*: %s", alert.Rule, alert.Message)}
+	case WebhookFormatTeams:
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  alert.Message,
+			"title":    alert.Rule,
+			"text":     alert.Message,
+		}
+	}
+	return alert
+}
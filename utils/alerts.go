@@ -0,0 +1,622 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AlertTypePrice    = "price"
+	AlertTypeFunding  = "funding"
+	AlertTypeMargin   = "margin"
+	AlertTypePosition = "position"
+	AlertTypeFill     = "fill"
+	AlertTypeTransfer = "transfer"
+
+	AlertChannelStdout  = "stdout"
+	AlertChannelCommand = "command"
+	AlertChannelSmtp    = "smtp"
+	AlertChannelWebhook = "webhook"
+
+	WebhookFormatJson  = "json"
+	WebhookFormatSlack = "slack"
+	WebhookFormatTeams = "teams"
+
+	defaultAlertInterval  = 30 * time.Second
+	defaultAlertCooldown  = 15 * time.Minute
+	alertTransferLookback = 24 * time.Hour
+	alertFillOverlap      = 5 * time.Minute
+)
+
+var alertPriceFields = []string{"mark", "index", "trade", "bid", "ask"}
+
+// AlertRules is the rules file of alerts run. Interval and cooldown apply to
+// rules that do not set their own.
+type AlertRules struct {
+	Interval time.Duration   `yaml:"interval"`
+	Cooldown *time.Duration  `yaml:"cooldown"`
+	Channels []*AlertChannel `yaml:"channels"`
+	Rules    []*AlertRule    `yaml:"rules"`
+}
+
+// AlertChannel is a destination for alerts. Webhooks post JSON formatted for
+// Slack, Teams or as the raw alert; commands run with sh -c and receive the
+// alert as JSON on stdin and in INTX_ALERT_* environment variables.
+type AlertChannel struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`
+	Url     string            `yaml:"url"`
+	Format  string            `yaml:"format"`
+	Headers map[string]string `yaml:"headers"`
+	Command string            `yaml:"command"`
+	Smtp    *SmtpSettings     `yaml:"smtp"`
+}
+
+// SmtpSettings configures email delivery. The password is read from the
+// environment variable named by PasswordEnv so it stays out of the file.
+type SmtpSettings struct {
+	Host        string   `yaml:"host"`
+	Port        int      `yaml:"port"`
+	Username    string   `yaml:"username"`
+	PasswordEnv string   `yaml:"passwordEnv"`
+	From        string   `yaml:"from"`
+	To          []string `yaml:"to"`
+}
+
+// AlertRule is one condition to watch. Price rules fire when the price of the
+// instrument crosses above or below a level, funding and margin rules when
+// the predicted funding rate or margin usage is above or below it. Position,
+// fill and transfer rules fire on position size changes, new fills and failed
+// transfers of the portfolio.
+type AlertRule struct {
+	Name       string         `yaml:"name"`
+	Type       string         `yaml:"type"`
+	Instrument string         `yaml:"instrument"`
+	Price      string         `yaml:"price"`
+	Portfolio  string         `yaml:"portfolio"`
+	Above      *float64       `yaml:"above"`
+	Below      *float64       `yaml:"below"`
+	Interval   time.Duration  `yaml:"interval"`
+	Cooldown   *time.Duration `yaml:"cooldown"`
+	Channels   []string       `yaml:"channels"`
+}
+
+type Alert struct {
+	Rule     string    `json:"rule"`
+	Type     string    `json:"type"`
+	Subject  string    `json:"subject"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+	channels []string
+}
+
+func FormatAlert(alert *Alert) string {
+	return fmt.Sprintf("%s  [%s]  %s", alert.Time.UTC().Format(time.RFC3339), alert.Rule, alert.Message)
+}
+
+func LoadAlertRules(path string) (*AlertRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rules: %w", err)
+	}
+
+	rules := &AlertRules{}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("cannot parse rules: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *AlertRules) Validate() error {
+	if r.Interval < 0 || (r.Cooldown != nil && *r.Cooldown < 0) {
+		return errors.New("interval and cooldown cannot be negative")
+	}
+
+	channels := map[string]bool{}
+	for _, c := range r.Channels {
+		if c.Name == "" {
+			return errors.New("name is required for every channel")
+		}
+		if channels[c.Name] {
+			return fmt.Errorf("channel %s is listed more than once", c.Name)
+		}
+		channels[c.Name] = true
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("channel %s: %w", c.Name, err)
+		}
+	}
+
+	if len(r.Rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+	names := map[string]bool{}
+	for _, rule := range r.Rules {
+		if rule.Name == "" {
+			return errors.New("name is required for every rule")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %s is listed more than once", rule.Name)
+		}
+		names[rule.Name] = true
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		for _, c := range rule.Channels {
+			if !channels[c] {
+				return fmt.Errorf("rule %s: unknown channel %s", rule.Name, c)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *AlertChannel) validate() error {
+	switch c.Type {
+	case AlertChannelStdout:
+	case AlertChannelCommand:
+		if c.Command == "" {
+			return errors.New("command is required")
+		}
+	case AlertChannelSmtp:
+		if c.Smtp == nil || c.Smtp.Host == "" || c.Smtp.From == "" || len(c.Smtp.To) == 0 {
+			return errors.New("smtp host, from and to are required")
+		}
+	case AlertChannelWebhook:
+		if c.Url == "" {
+			return errors.New("url is required")
+		}
+		switch c.Format {
+		case "", WebhookFormatJson, WebhookFormatSlack, WebhookFormatTeams:
+		default:
+			return fmt.Errorf("unsupported webhook format %s: use json, slack or teams", c.Format)
+		}
+	default:
+		return fmt.Errorf("unsupported type %s: use stdout, command, smtp or webhook", c.Type)
+	}
+	return nil
+}
+
+func (rule *AlertRule) validate() error {
+	if rule.Interval < 0 || (rule.Cooldown != nil && *rule.Cooldown < 0) {
+		return errors.New("interval and cooldown cannot be negative")
+	}
+
+	switch rule.Type {
+	case AlertTypePrice, AlertTypeFunding:
+		if rule.Instrument == "" {
+			return errors.New("instrument is required")
+		}
+		if rule.Type == AlertTypePrice && rule.Price != "" && !slices.Contains(alertPriceFields, rule.Price) {
+			return fmt.Errorf("unsupported price %s: use %s", rule.Price, strings.Join(alertPriceFields, ", "))
+		}
+		fallthrough
+	case AlertTypeMargin:
+		if rule.Above == nil && rule.Below == nil {
+			return errors.New("above or below is required")
+		}
+	case AlertTypePosition, AlertTypeFill, AlertTypeTransfer:
+	default:
+		return fmt.Errorf("unsupported type %s", rule.Type)
+	}
+	return nil
+}
+
+// NeedsPortfolio reports whether a rule relies on the default portfolio.
+func (r *AlertRules) NeedsPortfolio() bool {
+	for _, rule := range r.Rules {
+		if rule.Portfolio == "" && rule.Type != AlertTypePrice && rule.Type != AlertTypeFunding {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AlertRules) interval(rule *AlertRule) time.Duration {
+	if rule.Interval > 0 {
+		return rule.Interval
+	}
+	if r.Interval > 0 {
+		return r.Interval
+	}
+	return defaultAlertInterval
+}
+
+func (r *AlertRules) cooldown(rule *AlertRule) time.Duration {
+	if rule.Cooldown != nil {
+		return *rule.Cooldown
+	}
+	if r.Cooldown != nil {
+		return *r.Cooldown
+	}
+	return defaultAlertCooldown
+}
+
+// AlertEvaluation is the outcome of one evaluation. Suppressed alerts were
+// raised within the cooldown of the same rule and subject.
+type AlertEvaluation struct {
+	Alerts     []*Alert
+	Suppressed []*Alert
+	Errors     []error
+}
+
+// AlertEngine evaluates alert rules on their schedule. Threshold rules alert
+// once when their condition starts to hold and re-arm when it stops; event
+// rules alert once per event, after a first evaluation that only records the
+// current state.
+type AlertEngine struct {
+	rules       *AlertRules
+	client      *intx.Client
+	portfolioId string
+	start       time.Time
+	states      map[*AlertRule]*alertRuleState
+	lastSent    map[string]time.Time
+}
+
+type alertRuleState struct {
+	next        time.Time
+	initialized bool
+	active      map[string]bool
+	sizes       map[string]float64
+	seen        map[string]time.Time
+	since       time.Time
+}
+
+func NewAlertEngine(rules *AlertRules, client *intx.Client, portfolioId string, start time.Time) *AlertEngine {
+	e := &AlertEngine{
+		rules:       rules,
+		client:      client,
+		portfolioId: portfolioId,
+		start:       start,
+		states:      map[*AlertRule]*alertRuleState{},
+		lastSent:    map[string]time.Time{},
+	}
+	for _, rule := range rules.Rules {
+		e.states[rule] = &alertRuleState{
+			next:   start,
+			active: map[string]bool{},
+			sizes:  map[string]float64{},
+			seen:   map[string]time.Time{},
+			since:  start,
+		}
+	}
+	return e
+}
+
+// NextRun returns when the next rule is due.
+func (e *AlertEngine) NextRun() time.Time {
+	var next time.Time
+	for _, state := range e.states {
+		if next.IsZero() || state.next.Before(next) {
+			next = state.next
+		}
+	}
+	return next
+}
+
+// Evaluate runs the rules due at now. A rule that fails is retried at its
+// next run.
+func (e *AlertEngine) Evaluate(now time.Time) *AlertEvaluation {
+	evaluation := &AlertEvaluation{}
+	cycle := &alertCycle{
+		client:    e.client,
+		quotes:    map[string]*intx.Quote{},
+		summaries: map[string]*intx.Summary{},
+		positions: map[string][]intx.Position{},
+	}
+
+	for _, rule := range e.rules.Rules {
+		state := e.states[rule]
+		if now.Before(state.next) {
+			continue
+		}
+		state.next = now.Add(e.rules.interval(rule))
+
+		alerts, err := e.evaluateRule(cycle, rule, state, now)
+		if err != nil {
+			evaluation.Errors = append(evaluation.Errors, fmt.Errorf("rule %s: %w", rule.Name, err))
+			continue
+		}
+		state.initialized = true
+
+		for _, alert := range alerts {
+			alert.Rule = rule.Name
+			alert.Type = rule.Type
+			alert.Time = now
+			alert.channels = rule.Channels
+
+			key := rule.Name + "|" + alert.Subject
+			if last, ok := e.lastSent[key]; ok && now.Sub(last) < e.rules.cooldown(rule) {
+				evaluation.Suppressed = append(evaluation.Suppressed, alert)
+				continue
+			}
+			e.lastSent[key] = now
+			evaluation.Alerts = append(evaluation.Alerts, alert)
+		}
+	}
+	return evaluation
+}
+
+func (e *AlertEngine) evaluateRule(cycle *alertCycle, rule *AlertRule, state *alertRuleState, now time.Time) ([]*Alert, error) {
+	portfolioId := rule.Portfolio
+	if portfolioId == "" {
+		portfolioId = e.portfolioId
+	}
+
+	switch rule.Type {
+	case AlertTypePrice:
+		quote, err := cycle.quote(rule.Instrument)
+		if err != nil {
+			return nil, err
+		}
+		field := rule.Price
+		if field == "" {
+			field = alertPriceFields[0]
+		}
+		value := quotePrice(quote, field)
+		label := fmt.Sprintf("%s %s price %s", rule.Instrument, field, FormatAmount(value))
+		return thresholdAlerts(rule, state, value, label, "crossed above", "crossed below", true), nil
+
+	case AlertTypeFunding:
+		quote, err := cycle.quote(rule.Instrument)
+		if err != nil {
+			return nil, err
+		}
+		value := ParseAmountOrZero(quote.PredictedFunding)
+		label := fmt.Sprintf("%s predicted funding rate %s", rule.Instrument, FormatAmount(value))
+		return thresholdAlerts(rule, state, value, label, "is above", "is below", false), nil
+
+	case AlertTypeMargin:
+		summary, err := cycle.summary(portfolioId)
+		if err != nil {
+			return nil, err
+		}
+		value := ratio(summary.PortfolioInitialMarginNotional, ParseAmountOrZero(summary.Collateral))
+		label := fmt.Sprintf("portfolio %s margin usage %s", portfolioId, FormatRounded(value, 4))
+		return thresholdAlerts(rule, state, value, label, "is above", "is below", false), nil
+
+	case AlertTypePosition:
+		positions, err := cycle.portfolioPositions(portfolioId)
+		if err != nil {
+			return nil, err
+		}
+		return positionAlerts(rule, state, portfolioId, positions), nil
+
+	case AlertTypeFill:
+		return e.fillAlerts(rule, state, portfolioId, now)
+
+	case AlertTypeTransfer:
+		return e.transferAlerts(rule, state, portfolioId, now)
+	}
+	return nil, fmt.Errorf("unsupported type %s", rule.Type)
+}
+
+// thresholdAlerts raises an alert for each bound that value newly exceeds.
+// When edgeOnly, a bound already exceeded on the first evaluation does not
+// alert, so only crossings are reported.
+func thresholdAlerts(rule *AlertRule, state *alertRuleState, value float64, label, aboveText, belowText string, edgeOnly bool) []*Alert {
+	var alerts []*Alert
+	check := func(subject string, bound *float64, met bool, text string) {
+		if bound == nil {
+			return
+		}
+		was, known := state.active[subject]
+		state.active[subject] = met
+		if met && !was && (known || !edgeOnly) {
+			alerts = append(alerts, &Alert{
+				Subject: subject,
+				Message: fmt.Sprintf("%s %s %s", label, text, FormatAmount(*bound)),
+			})
+		}
+	}
+	check("above", rule.Above, rule.Above != nil && value > *rule.Above, aboveText)
+	check("below", rule.Below, rule.Below != nil && value < *rule.Below, belowText)
+	return alerts
+}
+
+func positionAlerts(rule *AlertRule, state *alertRuleState, portfolioId string, positions []intx.Position) []*Alert {
+	sizes := map[string]float64{}
+	for _, p := range positions {
+		if rule.Instrument == "" || strings.EqualFold(p.Symbol, rule.Instrument) {
+			sizes[p.Symbol] += ParseAmountOrZero(p.NetSize)
+		}
+	}
+	for symbol := range state.sizes {
+		if _, ok := sizes[symbol]; !ok {
+			sizes[symbol] = 0
+		}
+	}
+
+	symbols := make([]string, 0, len(sizes))
+	for symbol := range sizes {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var alerts []*Alert
+	for _, symbol := range symbols {
+		size := sizes[symbol]
+		previous := state.sizes[symbol]
+		if state.initialized && size != previous {
+			alerts = append(alerts, &Alert{
+				Subject: symbol,
+				Message: fmt.Sprintf("portfolio %s %s position changed from %s to %s",
+					portfolioId, symbol, FormatAmount(previous), FormatAmount(size)),
+			})
+		}
+		if size == 0 {
+			delete(state.sizes, symbol)
+		} else {
+			state.sizes[symbol] = size
+		}
+	}
+	return alerts
+}
+
+func (e *AlertEngine) fillAlerts(rule *AlertRule, state *alertRuleState, portfolioId string, now time.Time) ([]*Alert, error) {
+	fills, err := ListAllFills(e.client, portfolioId, state.since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []*Alert
+	for _, f := range fills {
+		if _, ok := state.seen[f.FillId]; ok {
+			continue
+		}
+		state.seen[f.FillId] = now
+		if rule.Instrument != "" && !strings.EqualFold(f.Symbol, rule.Instrument) {
+			continue
+		}
+		alerts = append(alerts, &Alert{
+			Subject: f.FillId,
+			Message: fmt.Sprintf("portfolio %s new fill: %s %s %s @ %s (order %s)",
+				portfolioId, f.Side, f.FillQty, f.Symbol, f.FillPrice, f.OrderId),
+		})
+	}
+
+	// Fills seen before the next lookup window cannot be returned again.
+	state.since = now.Add(-alertFillOverlap)
+	for id, seen := range state.seen {
+		if seen.Before(state.since) {
+			delete(state.seen, id)
+		}
+	}
+	return alerts, nil
+}
+
+// transferAlerts looks up the transfers created within alertTransferLookback
+// of the previous evaluation, so that transfers failing some time after they
+// were created are still found.
+func (e *AlertEngine) transferAlerts(rule *AlertRule, state *alertRuleState, portfolioId string, now time.Time) ([]*Alert, error) {
+	from := state.since.Add(-alertTransferLookback)
+	transfers, err := ListAllTransfers(e.client, TransferFilter{
+		PortfolioIds: portfolioId,
+		TimeFrom:     from.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []*Alert
+	for _, t := range transfers {
+		if !strings.EqualFold(t.Status, TransferStatusFailed) {
+			continue
+		}
+		if _, ok := state.seen[t.TransferUuid]; ok {
+			continue
+		}
+		created, err := ParseTime(t.CreatedAt)
+		if err != nil {
+			created = now
+		}
+		state.seen[t.TransferUuid] = created
+		if state.initialized {
+			alerts = append(alerts, &Alert{
+				Subject: t.TransferUuid,
+				Message: fmt.Sprintf("portfolio %s transfer %s failed: %s %s %s",
+					portfolioId, t.TransferUuid, t.Type, FormatAmount(t.Amount), t.Asset),
+			})
+		}
+	}
+
+	// Transfers created before the next lookup window cannot be returned again.
+	state.since = now
+	from = now.Add(-alertTransferLookback)
+	for id, created := range state.seen {
+		if created.Before(from) {
+			delete(state.seen, id)
+		}
+	}
+	return alerts, nil
+}
+
+func quotePrice(quote *intx.Quote, field string) float64 {
+	switch field {
+	case "index":
+		return ParseAmountOrZero(quote.IndexPrice)
+	case "trade":
+		return ParseAmountOrZero(quote.TradePrice)
+	case "bid":
+		return ParseAmountOrZero(quote.BestBidPrice)
+	case "ask":
+		return ParseAmountOrZero(quote.BestAskPrice)
+	}
+	return ParseAmountOrZero(quote.MarkPrice)
+}
+
+// alertCycle caches API responses shared by the rules of one evaluation.
+type alertCycle struct {
+	client    *intx.Client
+	quotes    map[string]*intx.Quote
+	summaries map[string]*intx.Summary
+	positions map[string][]intx.Position
+}
+
+func (c *alertCycle) quote(instrumentId string) (*intx.Quote, error) {
+	if q, ok := c.quotes[instrumentId]; ok {
+		return q, nil
+	}
+	q, err := GetQuote(c.client, instrumentId)
+	if err != nil {
+		return nil, err
+	}
+	c.quotes[instrumentId] = q
+	return q, nil
+}
+
+func (c *alertCycle) summary(portfolioId string) (*intx.Summary, error) {
+	if s, ok := c.summaries[portfolioId]; ok {
+		return s, nil
+	}
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+	response, err := c.client.GetPortfolioSummary(ctx, &intx.GetPortfolioSummaryRequest{PortfolioId: portfolioId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get summary for %s: %w", portfolioId, err)
+	}
+	s := response.Summary
+	if s == nil {
+		s = &intx.Summary{}
+	}
+	c.summaries[portfolioId] = s
+	return s, nil
+}
+
+func (c *alertCycle) portfolioPositions(portfolioId string) ([]intx.Position, error) {
+	if p, ok := c.positions[portfolioId]; ok {
+		return p, nil
+	}
+	p, err := GetPositions(c.client, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+	c.positions[portfolioId] = p
+	return p, nil
+}
@@ -0,0 +1,117 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// CallApi sends a signed request for endpoints, or response shapes, that the
+// SDK does not cover. Authentication matches the SDK.
+func CallApi(ctx context.Context, client *intx.Client, method, path string, query url.Values, body, response interface{}) error {
+	callUrl := client.HttpBaseUrl + path
+	if len(query) > 0 {
+		callUrl += "?" + query.Encode()
+	}
+
+	parsedUrl, err := url.Parse(callUrl)
+	if err != nil {
+		return fmt.Errorf("invalid URL %s: %w", callUrl, err)
+	}
+
+	var requestBody []byte
+	if body != nil {
+		if requestBody, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("cannot marshal request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, callUrl, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	signature, err := signRequest(client.Credentials, method, parsedUrl.Path, timestamp, requestBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("CB-ACCESS-KEY", client.Credentials.AccessKey)
+	req.Header.Add("CB-ACCESS-PASSPHRASE", client.Credentials.Passphrase)
+	req.Header.Add("CB-ACCESS-SIGN", signature)
+	req.Header.Add("CB-ACCESS-TIMESTAMP", strconv.FormatInt(timestamp, 10))
+
+	res, err := client.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	responseBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected status code: %d - received: %d - url %s - msg: %s", http.StatusOK, res.StatusCode, callUrl, responseBody)
+	}
+
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return fmt.Errorf("cannot unmarshal response: %w", err)
+	}
+	return nil
+}
+
+var rejectedStatusPattern = regexp.MustCompile(`received: 4\d\d\b`)
+
+// IsRejectedRequest reports whether err, returned by the SDK or CallApi, is a
+// client error response, so the request was certainly not executed. Transport
+// errors and timeouts leave the outcome unknown.
+func IsRejectedRequest(err error) bool {
+	return err != nil && rejectedStatusPattern.MatchString(err.Error())
+}
+
+func signRequest(credentials *intx.Credentials, method, path string, timestamp int64, body []byte) (string, error) {
+	if credentials == nil {
+		return "", fmt.Errorf("credentials not set")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(credentials.SigningKey)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode signing key: %w", err)
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(strconv.FormatInt(timestamp, 10) + method + path))
+	h.Write(body)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,291 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	AuditLogEnvVar   = "INTX_AUDIT_LOG"
+	auditLogFileName = "audit.jsonl"
+
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 15 * time.Second
+	// Writers hold a lock for milliseconds, so an older lock was left by a
+	// process that died while holding it.
+	lockStaleAge = 10 * time.Second
+)
+
+var (
+	auditMu      sync.Mutex
+	auditFailure error
+)
+
+type AuditRecord struct {
+	Sequence    int64           `json:"sequence"`
+	Timestamp   string          `json:"timestamp"`
+	User        string          `json:"user"`
+	Host        string          `json:"host"`
+	Profile     string          `json:"profile,omitempty"`
+	Environment string          `json:"environment"`
+	BaseUrl     string          `json:"baseUrl"`
+	Command     string          `json:"command"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	Outcome     string          `json:"outcome"`
+	Error       string          `json:"error,omitempty"`
+	PrevHash    string          `json:"prevHash"`
+	Hash        string          `json:"hash,omitempty"`
+}
+
+func (r *AuditRecord) ComputeHash() (string, error) {
+	unhashed := *r
+	unhashed.Hash = ""
+
+	data, err := json.Marshal(unhashed)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func GetAuditLogPath() (string, error) {
+	if path := os.Getenv(AuditLogEnvVar); path != "" {
+		return path, nil
+	}
+	return GetCliHomePath(auditLogFileName)
+}
+
+// RecordAudit appends the outcome of a mutating command to the audit log.
+// Failures to write do not hide the API result; they make the command fail
+// afterwards through TakeAuditFailure.
+func RecordAudit(cmd *cobra.Command, request, response interface{}, callErr error) {
+	RecordAuditAction(cmd, cmd.CommandPath(), request, response, callErr)
+}
+
+// RecordAuditAction is RecordAudit for actions that are not commands of their
+// own, such as orders placed from the terminal UI.
+func RecordAuditAction(cmd *cobra.Command, action string, request, response interface{}, callErr error) {
+	if err := AppendAuditRecord(cmd, action, request, response, callErr); err != nil {
+		auditMu.Lock()
+		if auditFailure == nil {
+			auditFailure = err
+		}
+		auditMu.Unlock()
+	}
+}
+
+// TakeAuditFailure returns an error if an audit record could not be written
+// since the last call, and clears it.
+func TakeAuditFailure() error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	err := auditFailure
+	auditFailure = nil
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Code: ExitCodeAudit, Err: fmt.Errorf("result not recorded in the audit log: %w", err)}
+}
+
+func AppendAuditRecord(cmd *cobra.Command, action string, request, response interface{}, callErr error) error {
+	path, err := GetAuditLogPath()
+	if err != nil {
+		return err
+	}
+
+	record := &AuditRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		User:      currentUsername(),
+		Command:   action,
+		Outcome:   AuditOutcomeSuccess,
+	}
+	record.Host, _ = os.Hostname()
+
+	if environment, err := ResolveEnvironment(cmd); err == nil {
+		record.Profile = environment.Profile
+		record.Environment = environment.Name
+		record.BaseUrl = environment.BaseUrl
+	}
+
+	if record.Request, err = json.Marshal(request); err != nil {
+		return fmt.Errorf("cannot marshal request: %w", err)
+	}
+
+	if callErr != nil {
+		record.Outcome = AuditOutcomeFailure
+		record.Error = callErr.Error()
+	} else if record.Response, err = json.Marshal(response); err != nil {
+		return fmt.Errorf("cannot marshal response: %w", err)
+	}
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := ReadAuditRecords(path)
+	if err != nil {
+		return err
+	}
+
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		record.Sequence = last.Sequence + 1
+		record.PrevHash = last.Hash
+	}
+
+	if record.Hash, err = record.ComputeHash(); err != nil {
+		return fmt.Errorf("cannot hash audit record: %w", err)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("cannot marshal audit record: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("cannot append to audit log: %w", err)
+	}
+	return f.Sync()
+}
+
+func ReadAuditRecords(path string) ([]*AuditRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var records []*AuditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		record := &AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("cannot parse audit log line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read audit log: %w", err)
+	}
+	return records, nil
+}
+
+// VerifyAuditRecords checks sequence numbers, hash links and record hashes,
+// returning an error describing the first break in the chain.
+func VerifyAuditRecords(records []*AuditRecord) error {
+	prevHash := ""
+	for i, record := range records {
+		if record.Sequence != int64(i) {
+			return fmt.Errorf("record %d: expected sequence %d, found %d", i, i, record.Sequence)
+		}
+		if record.PrevHash != prevHash {
+			return fmt.Errorf("record %d: previous hash does not match record %d", i, i-1)
+		}
+		hash, err := record.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("record %d: cannot hash: %w", i, err)
+		}
+		if hash != record.Hash {
+			return fmt.Errorf("record %d: hash mismatch, record has been modified", i)
+		}
+		prevHash = record.Hash
+	}
+	return nil
+}
+
+func currentUsername() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return firstNonEmpty(os.Getenv("USER"), os.Getenv("USERNAME"))
+}
+
+// lockFile serializes writers of path using an adjacent lock file. A lock
+// older than lockStaleAge is removed, so a crash while holding it does not
+// block later writers.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("cannot lock %s: %w", path, err)
+		}
+		if removeStaleLock(lockPath) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// removeStaleLock removes lockPath if it is older than lockStaleAge and has
+// not been replaced in the meantime.
+func removeStaleLock(lockPath string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) < lockStaleAge {
+		return false
+	}
+	if current, err := os.Stat(lockPath); err != nil || !os.SameFile(info, current) || !current.ModTime().Equal(info.ModTime()) {
+		return false
+	}
+	if err := os.Remove(lockPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "WARNING: removed stale lock %s\n", lockPath)
+	return true
+}
@@ -0,0 +1,303 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	marketDataChannelLevel2 = "LEVEL2"
+	marketDataTypeSnapshot  = "SNAPSHOT"
+	marketDataTypeReject    = "REJECT"
+	marketDataSignatureTag  = "CBINTLMD"
+	marketDataOrigin        = "https://intxctl.local"
+)
+
+// BookLevel is the resting size at one price.
+type BookLevel struct {
+	Price float64 `json:"price"`
+	Size  float64 `json:"size"`
+}
+
+// OrderBook is a depth snapshot with bids and asks each sorted from the best
+// price outwards.
+type OrderBook struct {
+	Instrument string       `json:"instrument"`
+	Time       string       `json:"time"`
+	Sequence   int64        `json:"sequence"`
+	Bids       []*BookLevel `json:"bids"`
+	Asks       []*BookLevel `json:"asks"`
+}
+
+type marketDataMessage struct {
+	Channel   string     `json:"channel"`
+	Type      string     `json:"type"`
+	ProductId string     `json:"product_id"`
+	Sequence  int64      `json:"sequence"`
+	Time      string     `json:"time"`
+	Message   string     `json:"message"`
+	Reason    string     `json:"reason"`
+	Bids      [][]string `json:"bids"`
+	Asks      [][]string `json:"asks"`
+}
+
+type marketDataSubscription struct {
+	Type       string   `json:"type"`
+	ProductIds []string `json:"product_ids"`
+	Channels   []string `json:"channels"`
+	Time       string   `json:"time"`
+	Key        string   `json:"key"`
+	Passphrase string   `json:"passphrase"`
+	Signature  string   `json:"signature"`
+}
+
+// FetchOrderBook subscribes to the LEVEL2 channel of the market data feed at
+// feedUrl with the credentials of client and returns the first snapshot of
+// instrument.
+func FetchOrderBook(ctx context.Context, client *intx.Client, feedUrl, instrument string) (*OrderBook, error) {
+	if client.Credentials == nil {
+		return nil, errors.New("credentials not set")
+	}
+
+	config, err := websocket.NewConfig(feedUrl, marketDataOrigin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid market data URL %s: %w", feedUrl, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(getDefaultTimeoutDuration())
+	}
+	config.Dialer = &net.Dialer{Deadline: deadline}
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to market data feed: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	subscription, err := signMarketDataSubscription(client.Credentials, instrument, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := websocket.JSON.Send(conn, subscription); err != nil {
+		return nil, fmt.Errorf("cannot subscribe to %s: %w", instrument, err)
+	}
+
+	for {
+		message := &marketDataMessage{}
+		if err := websocket.JSON.Receive(conn, message); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return nil, fmt.Errorf("cannot read market data: %w", err)
+		}
+
+		if strings.EqualFold(message.Type, marketDataTypeReject) {
+			return nil, fmt.Errorf("market data subscription rejected: %s", firstNonEmpty(message.Message, message.Reason))
+		}
+		if !strings.EqualFold(message.Channel, marketDataChannelLevel2) ||
+			!strings.EqualFold(message.Type, marketDataTypeSnapshot) ||
+			!strings.EqualFold(message.ProductId, instrument) {
+			continue
+		}
+
+		book := &OrderBook{Instrument: message.ProductId, Time: message.Time, Sequence: message.Sequence}
+		if book.Bids, err = parseBookLevels(message.Bids); err != nil {
+			return nil, fmt.Errorf("invalid bids: %w", err)
+		}
+		if book.Asks, err = parseBookLevels(message.Asks); err != nil {
+			return nil, fmt.Errorf("invalid asks: %w", err)
+		}
+		sort.SliceStable(book.Bids, func(i, j int) bool { return book.Bids[i].Price > book.Bids[j].Price })
+		sort.SliceStable(book.Asks, func(i, j int) bool { return book.Asks[i].Price < book.Asks[j].Price })
+		return book, nil
+	}
+}
+
+// signMarketDataSubscription signs the timestamp, key, feed tag and
+// passphrase with the signing key, as the market data feed expects.
+func signMarketDataSubscription(credentials *intx.Credentials, instrument string, now time.Time) (*marketDataSubscription, error) {
+	key, err := base64.StdEncoding.DecodeString(credentials.SigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode signing key: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(timestamp + credentials.AccessKey + marketDataSignatureTag + credentials.Passphrase))
+
+	return &marketDataSubscription{
+		Type:       "SUBSCRIBE",
+		ProductIds: []string{instrument},
+		Channels:   []string{marketDataChannelLevel2},
+		Time:       timestamp,
+		Key:        credentials.AccessKey,
+		Passphrase: credentials.Passphrase,
+		Signature:  base64.StdEncoding.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+func parseBookLevels(raw [][]string) ([]*BookLevel, error) {
+	levels := []*BookLevel{}
+	for _, r := range raw {
+		if len(r) < 2 {
+			return nil, fmt.Errorf("level %v needs a price and a size", r)
+		}
+		price, err := ParseAmount(r[0])
+		if err != nil {
+			return nil, err
+		}
+		size, err := ParseAmount(r[1])
+		if err != nil {
+			return nil, err
+		}
+		if size > 0 {
+			levels = append(levels, &BookLevel{Price: price, Size: size})
+		}
+	}
+	return levels, nil
+}
+
+// BookSpread describes the top of a book. Prices are zero for an empty side.
+type BookSpread struct {
+	BestBid   float64 `json:"bestBid"`
+	BestAsk   float64 `json:"bestAsk"`
+	Mid       float64 `json:"mid"`
+	Spread    float64 `json:"spread"`
+	SpreadBps float64 `json:"spreadBps"`
+}
+
+func (b *OrderBook) Spread() *BookSpread {
+	s := &BookSpread{}
+	if len(b.Bids) > 0 {
+		s.BestBid = b.Bids[0].Price
+	}
+	if len(b.Asks) > 0 {
+		s.BestAsk = b.Asks[0].Price
+	}
+	if s.BestBid > 0 && s.BestAsk > 0 {
+		s.Mid = (s.BestBid + s.BestAsk) / 2
+		s.Spread = s.BestAsk - s.BestBid
+		s.SpreadBps = s.Spread / s.Mid * 1e4
+	}
+	return s
+}
+
+// DepthLevel is a price bucket of a book side with the size and notional of
+// it and all better buckets.
+type DepthLevel struct {
+	Price              float64 `json:"price"`
+	Size               float64 `json:"size"`
+	CumulativeSize     float64 `json:"cumulativeSize"`
+	CumulativeNotional float64 `json:"cumulativeNotional"`
+}
+
+// GroupBookLevels merges levels, sorted from the best price outwards, into
+// buckets of increment. Bid prices round down and ask prices up, so a bucket
+// never looks better than the levels in it. Notional uses the level prices.
+// A zero increment keeps every level.
+func GroupBookLevels(levels []*BookLevel, increment float64, bids bool) []*DepthLevel {
+	grouped := []*DepthLevel{}
+	size, notional := 0.0, 0.0
+	for _, l := range levels {
+		price := l.Price
+		if increment > 0 {
+			steps := price / increment
+			if bids {
+				steps = math.Floor(steps + 1e-9)
+			} else {
+				steps = math.Ceil(steps - 1e-9)
+			}
+			price = steps * increment
+		}
+
+		size += l.Size
+		notional += l.Price * l.Size
+		if n := len(grouped); n > 0 && grouped[n-1].Price == price {
+			grouped[n-1].Size += l.Size
+			grouped[n-1].CumulativeSize = size
+			grouped[n-1].CumulativeNotional = notional
+			continue
+		}
+		grouped = append(grouped, &DepthLevel{Price: price, Size: l.Size, CumulativeSize: size, CumulativeNotional: notional})
+	}
+	return grouped
+}
+
+// ImpactEstimate is the outcome of a market order of Size sweeping one side
+// of a book. Filled is less than Size when the book is too thin. Slippage is
+// the cost of the average price against the mid, in basis points.
+type ImpactEstimate struct {
+	Side        string  `json:"side"`
+	Size        float64 `json:"size"`
+	Filled      float64 `json:"filled"`
+	AvgPrice    float64 `json:"avgPrice"`
+	WorstPrice  float64 `json:"worstPrice"`
+	SlippageBps float64 `json:"slippageBps"`
+}
+
+// EstimateImpact walks the asks for buys and the bids for sells.
+func (b *OrderBook) EstimateImpact(side string, size float64) *ImpactEstimate {
+	estimate := &ImpactEstimate{Side: strings.ToUpper(side), Size: size}
+	levels := b.Bids
+	if estimate.Side == SideBuy {
+		levels = b.Asks
+	}
+
+	notional := 0.0
+	for _, l := range levels {
+		if estimate.Filled >= size {
+			break
+		}
+		take := math.Min(l.Size, size-estimate.Filled)
+		estimate.Filled += take
+		notional += take * l.Price
+		estimate.WorstPrice = l.Price
+	}
+	if estimate.Filled == 0 {
+		return estimate
+	}
+
+	estimate.AvgPrice = notional / estimate.Filled
+	if mid := b.Spread().Mid; mid > 0 {
+		estimate.SlippageBps = (estimate.AvgPrice - mid) / mid * 1e4
+		if estimate.Side == SideSell {
+			estimate.SlippageBps = -estimate.SlippageBps
+		}
+	}
+	return estimate
+}
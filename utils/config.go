@@ -0,0 +1,277 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	EnvProduction = "production"
+	EnvSandbox    = "sandbox"
+	EnvCustom     = "custom"
+
+	ProductionBaseUrl = "https://api.international.coinbase.com/api/v1"
+	SandboxBaseUrl    = "https://api-n5e1.coinbase.com/api/v1"
+
+	ProductionMarketDataUrl = "wss://ws-md.international.coinbase.com"
+	SandboxMarketDataUrl    = "wss://ws-md.n5e2.coinbase.com"
+
+	CliHomeEnvVar     = "INTX_CLI_HOME"
+	ProfileEnvVar     = "INTX_PROFILE"
+	EnvironmentEnvVar = "INTX_ENV"
+	BaseUrlEnvVar     = "INTX_BASE_URL"
+	MarketDataEnvVar  = "INTX_MARKET_DATA_URL"
+	CredentialsEnvVar = "INTX_CREDENTIALS"
+
+	configFileName = "config.json"
+	defaultHomeDir = ".intxctl"
+
+	// MutatingAnnotation marks commands that change exchange state.
+	MutatingAnnotation = "intxctl/mutating"
+)
+
+type Profile struct {
+	Env            string `json:"env,omitempty"`
+	BaseUrl        string `json:"baseUrl,omitempty"`
+	MarketDataUrl  string `json:"marketDataUrl,omitempty"`
+	CredentialsEnv string `json:"credentialsEnv,omitempty"`
+}
+
+type Config struct {
+	DefaultProfile string              `json:"defaultProfile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// Environment is the resolved target of a command after flags, environment
+// variables and the selected profile have been applied.
+type Environment struct {
+	Profile        string
+	Name           string
+	BaseUrl        string
+	MarketDataUrl  string
+	CredentialsEnv string
+}
+
+// IsProduction reports whether the environment is production by name or
+// calls the production API.
+func (e *Environment) IsProduction() bool {
+	return e.Name == EnvProduction || strings.TrimSuffix(e.BaseUrl, "/") == ProductionBaseUrl
+}
+
+func GetCliHome() (string, error) {
+	if home := os.Getenv(CliHomeEnvVar); home != "" {
+		return home, nil
+	}
+
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(userHome, defaultHomeDir), nil
+}
+
+func GetCliHomePath(name string) (string, error) {
+	home, err := GetCliHome()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(home, 0700); err != nil {
+		return "", fmt.Errorf("cannot create %s: %w", home, err)
+	}
+	return filepath.Join(home, name), nil
+}
+
+func LoadConfig() (*Config, error) {
+	home, err := GetCliHome()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{Profiles: map[string]*Profile{}}
+
+	data, err := os.ReadFile(filepath.Join(home, configFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("cannot parse config: %w", err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	return config, nil
+}
+
+func GetProfileName(cmd *cobra.Command, config *Config) string {
+	if name := GetFlagStringValue(cmd, ProfileFlag); name != "" {
+		return name
+	}
+	if name := os.Getenv(ProfileEnvVar); name != "" {
+		return name
+	}
+	return config.DefaultProfile
+}
+
+// ResolveEnvironment applies, in order of precedence, the --env and
+// --base-url flags, the INTX_ENV and INTX_BASE_URL environment variables and
+// the selected profile. A production or sandbox environment given at a higher
+// precedence than a URL replaces that URL with its own, so that for example
+// --env sandbox never runs against a production base URL from the profile.
+// Production is used when nothing is configured.
+func ResolveEnvironment(cmd *cobra.Command) (*Environment, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	environment := &Environment{
+		Profile:        GetProfileName(cmd, config),
+		CredentialsEnv: CredentialsEnvVar,
+	}
+
+	profile := &Profile{}
+	if environment.Profile != "" {
+		p, ok := config.Profiles[environment.Profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %s not found in config", environment.Profile)
+		}
+		profile = p
+	}
+
+	if profile.CredentialsEnv != "" {
+		environment.CredentialsEnv = profile.CredentialsEnv
+	}
+
+	var namePrecedence, baseUrlPrecedence, marketDataPrecedence int
+	environment.Name, namePrecedence = firstSetting(GetFlagStringValue(cmd, EnvFlag), os.Getenv(EnvironmentEnvVar), profile.Env)
+	environment.BaseUrl, baseUrlPrecedence = firstSetting(GetFlagStringValue(cmd, BaseUrlFlag), os.Getenv(BaseUrlEnvVar), profile.BaseUrl)
+	environment.MarketDataUrl, marketDataPrecedence = firstSetting("", os.Getenv(MarketDataEnvVar), profile.MarketDataUrl)
+
+	switch strings.ToLower(environment.Name) {
+	case "":
+		environment.Name = environmentForBaseUrl(environment.BaseUrl)
+	case EnvProduction:
+		environment.Name = EnvProduction
+	case EnvSandbox:
+		environment.Name = EnvSandbox
+	case EnvCustom:
+		environment.Name = EnvCustom
+	default:
+		return nil, fmt.Errorf("invalid environment %s: must be production, sandbox or custom", environment.Name)
+	}
+
+	if environment.Name != EnvCustom {
+		if baseUrlPrecedence < namePrecedence {
+			environment.BaseUrl = ""
+		}
+		if marketDataPrecedence < namePrecedence {
+			environment.MarketDataUrl = ""
+		}
+		if known := environmentForBaseUrl(environment.BaseUrl); known != EnvCustom && known != environment.Name && environment.BaseUrl != "" {
+			return nil, fmt.Errorf("base URL %s belongs to %s, not the %s environment", environment.BaseUrl, known, environment.Name)
+		}
+	}
+
+	if environment.BaseUrl == "" {
+		switch environment.Name {
+		case EnvProduction:
+			environment.BaseUrl = ProductionBaseUrl
+		case EnvSandbox:
+			environment.BaseUrl = SandboxBaseUrl
+		case EnvCustom:
+			return nil, errors.New("base URL is required for the custom environment")
+		}
+	}
+
+	// The custom environment has no market data feed unless one is set, which
+	// only matters to commands that use it.
+	if environment.MarketDataUrl == "" {
+		switch environment.Name {
+		case EnvProduction:
+			environment.MarketDataUrl = ProductionMarketDataUrl
+		case EnvSandbox:
+			environment.MarketDataUrl = SandboxMarketDataUrl
+		}
+	}
+
+	return environment, nil
+}
+
+func environmentForBaseUrl(baseUrl string) string {
+	switch strings.TrimSuffix(baseUrl, "/") {
+	case "", ProductionBaseUrl:
+		return EnvProduction
+	case SandboxBaseUrl:
+		return EnvSandbox
+	default:
+		return EnvCustom
+	}
+}
+
+func IsMutatingCommand(cmd *cobra.Command) bool {
+	return cmd.Annotations[MutatingAnnotation] == "true"
+}
+
+// WarnIfProduction prints a banner to stderr when a mutating command is about
+// to run against production.
+func WarnIfProduction(cmd *cobra.Command) error {
+	if !IsMutatingCommand(cmd) {
+		return nil
+	}
+
+	environment, err := ResolveEnvironment(cmd)
+	if err != nil {
+		return err
+	}
+
+	if environment.IsProduction() {
+		fmt.Fprintf(os.Stderr, "WARNING: %s is running against INTX PRODUCTION (%s)\n", cmd.Name(), environment.BaseUrl)
+	}
+	return nil
+}
+
+// firstSetting returns the first non-empty value and its precedence, which is
+// higher for earlier values and 0 when all are empty.
+func firstSetting(values ...string) (string, int) {
+	for i, v := range values {
+		if v != "" {
+			return v, len(values) - i
+		}
+	}
+	return "", 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
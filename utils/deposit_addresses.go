@@ -0,0 +1,109 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const depositAddressesFileName = "deposit_addresses.json"
+
+// DepositAddress is an address generated with create-crypto-address.
+type DepositAddress struct {
+	PortfolioId  string `json:"portfolioId"`
+	AssetId      string `json:"assetId"`
+	NetworkArnId string `json:"networkArnId"`
+	Address      string `json:"address"`
+	Label        string `json:"label,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+type DepositAddresses struct {
+	Addresses []*DepositAddress `json:"addresses"`
+}
+
+func GetDepositAddressesPath() (string, error) {
+	return GetCliHomePath(depositAddressesFileName)
+}
+
+func LoadDepositAddresses() (*DepositAddresses, error) {
+	path, err := GetDepositAddressesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := &DepositAddresses{}
+	if err := ReadJsonFile(path, addresses); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// SaveDepositAddress records a generated address. Addresses already recorded
+// for the same portfolio and network are not duplicated, though a new label
+// replaces the old one.
+func SaveDepositAddress(address *DepositAddress) error {
+	path, err := GetDepositAddressesPath()
+	if err != nil {
+		return err
+	}
+
+	if address.CreatedAt == "" {
+		address.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	addresses := &DepositAddresses{}
+	return UpdateJsonFile(path, addresses, func() error {
+		for _, a := range addresses.Addresses {
+			if a.PortfolioId == address.PortfolioId && a.NetworkArnId == address.NetworkArnId && a.Address == address.Address {
+				if address.Label != "" {
+					a.Label = address.Label
+				}
+				return nil
+			}
+		}
+		addresses.Addresses = append(addresses.Addresses, address)
+		return nil
+	})
+}
+
+// Filter returns the addresses matching the non-blank arguments, newest
+// first.
+func (d *DepositAddresses) Filter(portfolioId, assetId, networkArnId string) []*DepositAddress {
+	var matches []*DepositAddress
+	for _, a := range d.Addresses {
+		if (portfolioId == "" || a.PortfolioId == portfolioId) &&
+			(assetId == "" || strings.EqualFold(a.AssetId, assetId)) &&
+			(networkArnId == "" || a.NetworkArnId == networkArnId) {
+			matches = append(matches, a)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].CreatedAt > matches[j].CreatedAt })
+	return matches
+}
+
+// Find returns the recorded address with the given address or label.
+func (d *DepositAddresses) Find(addressOrLabel string) *DepositAddress {
+	for _, a := range d.Addresses {
+		if a.Address == addressOrLabel || (a.Label != "" && a.Label == addressOrLabel) {
+			return a
+		}
+	}
+	return nil
+}
@@ -43,12 +43,21 @@ const (
 	TimeToFlag       = "time-to"
 	StatusFlag       = "status"
 	ToFlag           = "to"
+	FromFlag         = "from"
+	MethodFlag       = "method"
 
 	AmountFlag               = "amount"
 	NonceFlag                = "nonce"
 	CounterpartyIdFlag       = "counterparty-id"
 	AddNetworkFeeToTotalFlag = "add-network-fee-to-total"
 
+	ReferenceFlag      = "reference"
+	AllowDuplicateFlag = "allow-duplicate"
+
+	ToNameFlag  = "to-name"
+	LabelFlag   = "label"
+	EnabledFlag = "enabled"
+
 	MarginOverrideFlag = "margin-override"
 
 	JsonIndent = "  "
@@ -56,9 +65,60 @@ const (
 	FormatFlag = "format"
 	ToggleFlag = "toggle"
 
+	OutputFormatFlag = "output-format"
+	OutputFlag       = "output"
+	CommandFlag      = "command"
+	ConcurrencyFlag  = "concurrency"
+	WatchlistFlag    = "watchlist"
+	RefreshFlag      = "refresh"
+	AddFlag          = "add"
+	ShockFlag        = "shock"
+	PolicyFlag       = "policy"
+	YesFlag          = "yes"
+	DryRunFlag       = "dry-run"
+	AllFlag          = "all"
+	ToleranceFlag    = "tolerance"
+	TimezoneFlag     = "timezone"
+	IncrementalFlag  = "incremental"
+	DbFlag           = "db"
+
+	LedgerFlag         = "ledger"
+	PriceToleranceFlag = "price-tolerance"
+	SizeToleranceFlag  = "size-tolerance"
+	MatchedFlag        = "matched"
+
+	WaitFlag        = "wait"
+	TimeoutFlag     = "timeout"
+	IntervalFlag    = "interval"
+	MaxIntervalFlag = "max-interval"
+
+	RulesFlag = "rules"
+	SendFlag  = "send"
+
+	ListenFlag = "listen"
+	ConfigFlag = "config"
+	VarFlag    = "var"
+
+	ClosePositionsFlag = "close-positions"
+	SlippageFlag       = "slippage"
+	PctFlag            = "pct"
+	OffsetTicksFlag    = "offset-ticks"
+	NotionalFlag       = "notional"
+	PctBalanceFlag     = "pct-balance"
+	RiskFlag           = "risk"
+	GroupFlag          = "group"
+	DepthFlag          = "depth"
+	ImpactFlag         = "impact"
+
+	ProfileFlag = "profile"
+	EnvFlag     = "env"
+	BaseUrlFlag = "base-url"
+
 	PortfolioIdFlag   = "portfolioId"
 	ClientOrderIdFlag = "client-order-id"
 	EventTypeFlag     = "event-type"
 
 	ZeroInt = 0
+
+	DateLayout = "2006-01-02"
 )
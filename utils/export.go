@@ -0,0 +1,285 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	ExportFormatCsv     = "csv"
+	ExportFormatXlsx    = "xlsx"
+	ExportFormatParquet = "parquet"
+	ExportFormatOfx     = "ofx"
+
+	ofxTimeLayout = "20060102150405.000[0:GMT]"
+)
+
+type ExportColumn struct {
+	Name    string
+	Numeric bool
+}
+
+// ExportTable is a normalized export. Row values are strings, or float64 for
+// numeric columns. Transactions is the cash view used for OFX.
+type ExportTable struct {
+	Sheet        string
+	Columns      []ExportColumn
+	Rows         [][]interface{}
+	Transactions []*OfxTransaction
+}
+
+// OfxTransaction is one statement line. Positive amounts are credits.
+type OfxTransaction struct {
+	Id       string
+	Type     string
+	Posted   time.Time
+	Amount   float64
+	Currency string
+	Name     string
+	Memo     string
+}
+
+func (t *ExportTable) AddRow(values ...interface{}) {
+	t.Rows = append(t.Rows, values)
+}
+
+func ValidateExportFormat(format string) error {
+	switch format {
+	case ExportFormatCsv, ExportFormatXlsx, ExportFormatParquet, ExportFormatOfx:
+		return nil
+	default:
+		return fmt.Errorf("unsupported export format %s: must be csv, xlsx, parquet or ofx", format)
+	}
+}
+
+func WriteExport(w io.Writer, format string, table *ExportTable) error {
+	switch format {
+	case ExportFormatCsv:
+		return writeExportCsv(w, table)
+	case ExportFormatXlsx:
+		return writeExportXlsx(w, table)
+	case ExportFormatParquet:
+		return writeExportParquet(w, table)
+	case ExportFormatOfx:
+		return writeExportOfx(w, table)
+	default:
+		return ValidateExportFormat(format)
+	}
+}
+
+func formatExportValue(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return FormatAmount(f)
+	}
+	return fmt.Sprint(v)
+}
+
+func writeExportCsv(w io.Writer, table *ExportTable) error {
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, c := range table.Columns {
+		header = append(header, c.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = formatExportValue(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeExportXlsx(w io.Writer, table *ExportTable) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := table.Sheet
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	var header []interface{}
+	for _, c := range table.Columns {
+		header = append(header, c.Name)
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+
+	for i, row := range table.Rows {
+		row := row
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	_, err := f.WriteTo(w)
+	return err
+}
+
+func writeExportParquet(w io.Writer, table *ExportTable) error {
+	var schema []string
+	for _, c := range table.Columns {
+		if c.Numeric {
+			schema = append(schema, fmt.Sprintf("name=%s, type=DOUBLE", c.Name))
+		} else {
+			schema = append(schema, fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8", c.Name))
+		}
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return fmt.Errorf("cannot create parquet writer: %w", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, row := range table.Rows {
+		if err := pw.Write(row); err != nil {
+			return fmt.Errorf("cannot write parquet row: %w", err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("cannot finish parquet file: %w", err)
+	}
+	return nil
+}
+
+// writeExportOfx writes an OFX 2.2 bank statement per currency, which is the
+// statement type accounting tools import most widely.
+func writeExportOfx(w io.Writer, table *ExportTable) error {
+	byCurrency := map[string][]*OfxTransaction{}
+	for _, t := range table.Transactions {
+		byCurrency[t.Currency] = append(byCurrency[t.Currency], t)
+	}
+
+	var currencies []string
+	for c := range byCurrency {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	now := formatOfxTime(time.Now())
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	b.WriteString(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	b.WriteString("<OFX>\n")
+	fmt.Fprintf(&b, "<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", now)
+	b.WriteString("<BANKMSGSRSV1>\n")
+
+	for i, currency := range currencies {
+		transactions := byCurrency[currency]
+		sort.SliceStable(transactions, func(i, j int) bool { return transactions[i].Posted.Before(transactions[j].Posted) })
+
+		start, end := transactions[0].Posted, transactions[len(transactions)-1].Posted
+		fmt.Fprintf(&b, "<STMTTRNRS><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n", i+1)
+		fmt.Fprintf(&b, "<STMTRS><CURDEF>%s</CURDEF>\n", ofxEscape(currency))
+		fmt.Fprintf(&b, "<BANKACCTFROM><BANKID>INTX</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n", ofxEscape(table.Sheet+"-"+currency))
+		fmt.Fprintf(&b, "<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n", formatOfxTime(start), formatOfxTime(end))
+		for _, t := range transactions {
+			fmt.Fprintf(&b, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+				t.Type, formatOfxTime(t.Posted), FormatAmount(t.Amount), ofxEscape(t.Id), ofxEscape(truncate(t.Name, 32)), ofxEscape(truncate(t.Memo, 255)))
+		}
+		b.WriteString("</BANKTRANLIST>\n")
+		fmt.Fprintf(&b, "<LEDGERBAL><BALAMT>0</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n", formatOfxTime(end))
+		b.WriteString("</STMTRS></STMTTRNRS>\n")
+	}
+
+	b.WriteString("</BANKMSGSRSV1>\n</OFX>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatOfxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeLayout)
+}
+
+var ofxReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func ofxEscape(s string) string {
+	return ofxReplacer.Replace(s)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// ExportState remembers, per export kind and portfolio selection, the time of
+// the newest record exported so incremental exports continue from there.
+type ExportState struct {
+	LastExported map[string]string `json:"lastExported"`
+}
+
+func getExportStatePath() (string, error) {
+	return GetCliHomePath("export_state.json")
+}
+
+func LoadLastExported(key string) (time.Time, error) {
+	path, err := getExportStatePath()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	state := &ExportState{}
+	if err := ReadJsonFile(path, state); err != nil {
+		return time.Time{}, err
+	}
+	if value, ok := state.LastExported[key]; ok {
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return time.Time{}, nil
+}
+
+func SaveLastExported(key string, t time.Time) error {
+	path, err := getExportStatePath()
+	if err != nil {
+		return err
+	}
+
+	state := &ExportState{}
+	return UpdateJsonFile(path, state, func() error {
+		if state.LastExported == nil {
+			state.LastExported = map[string]string{}
+		}
+		state.LastExported[key] = t.UTC().Format(time.RFC3339Nano)
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	TransferTypeDeposit = "DEPOSIT"
+
+	exportTimeLayout = time.RFC3339Nano
+)
+
+var fillExportColumns = []ExportColumn{
+	{Name: "time"},
+	{Name: "portfolio_id"},
+	{Name: "portfolio_name"},
+	{Name: "fill_id"},
+	{Name: "order_id"},
+	{Name: "client_order_id"},
+	{Name: "instrument"},
+	{Name: "side"},
+	{Name: "quantity", Numeric: true},
+	{Name: "price", Numeric: true},
+	{Name: "notional", Numeric: true},
+	{Name: "quote_currency"},
+	{Name: "fee", Numeric: true},
+	{Name: "fee_currency"},
+}
+
+// BuildFillsExport normalizes fills, which must be sorted by time. Trade
+// notional is booked in the quote currency of the instrument and fees in
+// their own currency.
+func BuildFillsExport(fills []intx.Fill, quoteCurrencies map[string]string, loc *time.Location) (*ExportTable, error) {
+	table := &ExportTable{Sheet: "fills", Columns: fillExportColumns}
+
+	for _, f := range fills {
+		t, err := ParseTime(f.EventTime)
+		if err != nil {
+			return nil, fmt.Errorf("fill %s: %w", f.FillId, err)
+		}
+		qty := ParseAmountOrZero(f.FillQty)
+		price := ParseAmountOrZero(f.FillPrice)
+		fee := ParseAmountOrZero(f.Fee)
+		quote := quoteCurrencies[f.Symbol]
+
+		table.AddRow(
+			t.In(loc).Format(exportTimeLayout), f.PortfolioId, f.PortfolioName, f.FillId, f.OrderId, f.ClientOrderId,
+			f.Symbol, f.Side, qty, price, qty*price, quote, fee, f.FeeAsset,
+		)
+
+		cash := qty * price
+		trnType := "CREDIT"
+		if strings.EqualFold(f.Side, sideBuy) {
+			cash, trnType = -cash, "DEBIT"
+		}
+		memo := fmt.Sprintf("%s %s %s @ %s", f.Side, f.FillQty, f.Symbol, f.FillPrice)
+		table.Transactions = append(table.Transactions, &OfxTransaction{
+			Id: f.FillId, Type: trnType, Posted: t, Amount: cash, Currency: quote, Name: f.Symbol, Memo: memo,
+		})
+		if fee != 0 {
+			table.Transactions = append(table.Transactions, &OfxTransaction{
+				Id: f.FillId + "-fee", Type: "FEE", Posted: t, Amount: -fee, Currency: f.FeeAsset, Name: f.Symbol + " fee", Memo: memo,
+			})
+		}
+	}
+	return table, nil
+}
+
+var transferExportColumns = []ExportColumn{
+	{Name: "time"},
+	{Name: "transfer_uuid"},
+	{Name: "type"},
+	{Name: "status"},
+	{Name: "asset"},
+	{Name: "amount", Numeric: true},
+	{Name: "signed_amount", Numeric: true},
+	{Name: "from_portfolio"},
+	{Name: "to_portfolio"},
+	{Name: "from_counterparty_id"},
+	{Name: "to_counterparty_id"},
+	{Name: "network"},
+	{Name: "instrument_id"},
+	{Name: "position_id"},
+}
+
+// BuildTransfersExport normalizes transfers, which must be sorted by time.
+// The signed amount is the flow into the selected portfolios, so transfers
+// between two selected portfolios net to zero.
+func BuildTransfersExport(transfers []Transfer, selected map[string]bool, loc *time.Location) (*ExportTable, error) {
+	table := &ExportTable{Sheet: "transfers", Columns: transferExportColumns}
+
+	for _, tr := range transfers {
+		t, err := ParseTime(tr.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("transfer %s: %w", tr.TransferUuid, err)
+		}
+		signed := SignedTransferAmount(tr, selected)
+		instrumentId := ""
+		if tr.InstrumentId != 0 {
+			instrumentId = strconv.FormatInt(tr.InstrumentId, 10)
+		}
+
+		table.AddRow(
+			t.In(loc).Format(exportTimeLayout), tr.TransferUuid, tr.Type, tr.Status, tr.Asset, tr.Amount, signed,
+			firstNonEmpty(tr.FromPortfolio.Id, tr.FromPortfolio.Name), firstNonEmpty(tr.ToPortfolio.Id, tr.ToPortfolio.Name),
+			tr.FromCounterpartyId, tr.ToCounterpartyId, tr.NetworkName, instrumentId, tr.PositionId,
+		)
+
+		if signed == 0 || strings.EqualFold(tr.Status, TransferStatusFailed) {
+			continue
+		}
+		trnType := "CREDIT"
+		switch {
+		case strings.EqualFold(tr.Type, TransferTypeDeposit):
+			trnType = "DEP"
+		case strings.EqualFold(tr.Type, TransferTypeInternal):
+			trnType = "XFER"
+		case signed < 0:
+			trnType = "DEBIT"
+		}
+		table.Transactions = append(table.Transactions, &OfxTransaction{
+			Id: tr.TransferUuid, Type: trnType, Posted: t, Amount: signed, Currency: tr.Asset, Name: tr.Type, Memo: tr.Status,
+		})
+	}
+	return table, nil
+}
+
+func SignedTransferAmount(t Transfer, selected map[string]bool) float64 {
+	from := isSelected(t.FromPortfolio, selected)
+	to := isSelected(t.ToPortfolio, selected)
+	switch {
+	case from && to:
+		return 0
+	case from:
+		return -t.Amount
+	case to:
+		return t.Amount
+	case strings.EqualFold(t.Type, TransferTypeWithdraw):
+		return -t.Amount
+	default:
+		return t.Amount
+	}
+}
+
+func isSelected(p intx.PortfolioSubset, selected map[string]bool) bool {
+	return (p.Id != "" && selected[p.Id]) || (p.Uuid != "" && selected[p.Uuid]) || (p.Name != "" && selected[p.Name])
+}
@@ -0,0 +1,128 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// CollectExchangeMetrics gathers the state of portfolios and the quotes of
+// instruments, and of every instrument with an open position. Portfolios or
+// quotes that fail are left out and their errors returned.
+func CollectExchangeMetrics(client *intx.Client, portfolios []*intx.Portfolio, instruments []string, concurrency int) (*MetricSet, []error) {
+	start := time.Now()
+	metrics := NewMetricSet()
+	var errs []error
+
+	quoted := map[string]bool{}
+	for _, id := range instruments {
+		quoted[id] = true
+	}
+
+	states, stateErrs := FetchPortfolioStates(client, portfolios, concurrency)
+	for i, state := range states {
+		if stateErrs[i] != nil {
+			errs = append(errs, stateErrs[i])
+			continue
+		}
+		addPortfolioMetrics(metrics, state)
+		for _, p := range state.Positions {
+			if ParseAmountOrZero(p.NetSize) != 0 {
+				quoted[p.Symbol] = true
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(quoted))
+	for id := range quoted {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		quote, err := GetQuote(client, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		addQuoteMetrics(metrics, id, quote)
+	}
+
+	success := 1.0
+	if len(errs) > 0 {
+		success = 0
+	}
+	metrics.Gauge("intx_collect_success", "Whether the last collection completed without errors.", success)
+	metrics.Gauge("intx_collect_duration_seconds", "Duration of the last collection.", time.Since(start).Seconds())
+	metrics.Gauge("intx_collect_timestamp_seconds", "Unix time of the last collection.", float64(start.Unix()))
+	return metrics, errs
+}
+
+func addPortfolioMetrics(metrics *MetricSet, state *PortfolioState) {
+	id := state.Portfolio.PortfolioId
+	o := ComputeOverview([]*PortfolioState{state}).Portfolios[0]
+
+	metrics.Gauge("intx_portfolio_info", "Portfolio names by ID.", 1, "portfolio", id, "name", state.Portfolio.Name)
+	metrics.Gauge("intx_collateral", "Portfolio collateral.", o.Collateral, "portfolio", id)
+	metrics.Gauge("intx_unrealized_pnl", "Portfolio unrealized PnL.", o.UnrealizedPnl, "portfolio", id)
+	metrics.Gauge("intx_initial_margin", "Portfolio initial margin notional.", o.InitialMargin, "portfolio", id)
+	metrics.Gauge("intx_maintenance_margin", "Portfolio maintenance margin notional.", o.MaintenanceMargin, "portfolio", id)
+	metrics.Gauge("intx_margin_usage", "Initial margin as a fraction of collateral.", o.MarginUsage, "portfolio", id)
+	metrics.Gauge("intx_in_liquidation", "Whether the portfolio is in liquidation.", boolMetric(o.InLiquidation), "portfolio", id)
+	metrics.Gauge("intx_open_orders", "Number of open orders.", float64(o.OpenOrders), "portfolio", id)
+
+	for _, b := range state.Balances {
+		asset := firstNonEmpty(b.AssetName, b.AssetId)
+		metrics.Gauge("intx_balance", "Asset balance.", ParseAmountOrZero(b.Quantity), "portfolio", id, "asset", asset)
+		metrics.Gauge("intx_balance_hold", "Asset balance on hold.", ParseAmountOrZero(b.Hold), "portfolio", id, "asset", asset)
+		metrics.Gauge("intx_balance_collateral_value", "Collateral value of the asset balance.", ParseAmountOrZero(b.CollateralValue), "portfolio", id, "asset", asset)
+	}
+
+	for _, p := range state.Positions {
+		size := ParseAmountOrZero(p.NetSize)
+		if size == 0 {
+			continue
+		}
+		metrics.Gauge("intx_position_size", "Net position size.", size, "portfolio", id, "instrument", p.Symbol)
+		metrics.Gauge("intx_position_notional", "Signed position notional at the mark price.", size*ParseAmountOrZero(p.MarkPrice), "portfolio", id, "instrument", p.Symbol)
+		metrics.Gauge("intx_position_unrealized_pnl", "Position unrealized PnL.", ParseAmountOrZero(p.UnrealizedPnl), "portfolio", id, "instrument", p.Symbol)
+	}
+}
+
+func addQuoteMetrics(metrics *MetricSet, instrument string, quote *intx.Quote) {
+	mark := ParseAmountOrZero(quote.MarkPrice)
+	bid := ParseAmountOrZero(quote.BestBidPrice)
+	ask := ParseAmountOrZero(quote.BestAskPrice)
+	mid := mark
+	if bid > 0 && ask > 0 {
+		mid = (bid + ask) / 2
+	}
+
+	metrics.Gauge("intx_quote_mid", "Mid of the best bid and ask, or the mark price without a two-sided book.", mid, "instrument", instrument)
+	metrics.Gauge("intx_quote_mark", "Mark price.", mark, "instrument", instrument)
+	metrics.Gauge("intx_quote_index", "Index price.", ParseAmountOrZero(quote.IndexPrice), "instrument", instrument)
+	metrics.Gauge("intx_funding_rate_predicted", "Predicted funding rate.", ParseAmountOrZero(quote.PredictedFunding), "instrument", instrument)
+}
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
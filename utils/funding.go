@@ -0,0 +1,225 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	InstrumentTypePerp = "PERP"
+
+	hoursPerYear = 24 * 365
+)
+
+type fundingRatesPage struct {
+	Pagination intx.PaginationSubset        `json:"pagination"`
+	Results    []intx.HistoricalFundingRate `json:"results"`
+}
+
+// ListFundingRates returns the funding rate history of an instrument, newest
+// first, stopping at from or after limit rates when either is set. The SDK's
+// GetHistoricalFundingRates only decodes a single rate.
+func ListFundingRates(client *intx.Client, instrumentId string, from time.Time, limit int) ([]intx.HistoricalFundingRate, error) {
+	var rates []intx.HistoricalFundingRate
+	for offset := 0; ; offset += PageSize {
+		query := url.Values{}
+		query.Set("result_limit", strconv.Itoa(PageSize))
+		query.Set("result_offset", strconv.Itoa(offset))
+
+		page := &fundingRatesPage{}
+		ctx, cancel := GetContextWithTimeout()
+		err := CallApi(ctx, client, "GET", fmt.Sprintf("/instruments/%s/funding", instrumentId), query, nil, page)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get funding rates for %s: %w", instrumentId, err)
+		}
+
+		for _, r := range page.Results {
+			if !from.IsZero() {
+				if eventTime, err := time.Parse(time.RFC3339Nano, r.EventTime); err == nil && eventTime.Before(from) {
+					return rates, nil
+				}
+			}
+			rates = append(rates, r)
+			if limit > 0 && len(rates) >= limit {
+				return rates, nil
+			}
+		}
+
+		if len(page.Results) < PageSize {
+			return rates, nil
+		}
+	}
+}
+
+// PositionSizeAt reconstructs the signed position size of an instrument at t
+// by unwinding the fills after t from the current size.
+func PositionSizeAt(currentSize float64, fills []intx.Fill, t time.Time) float64 {
+	size := currentSize
+	for _, f := range fills {
+		eventTime, err := time.Parse(time.RFC3339Nano, f.EventTime)
+		if err != nil || !eventTime.After(t) {
+			continue
+		}
+		qty := ParseAmountOrZero(f.FillQty)
+		if strings.EqualFold(f.Side, sideBuy) {
+			size -= qty
+		} else {
+			size += qty
+		}
+	}
+	return size
+}
+
+// FundingPayment is the amount received by a position of signed size for one
+// funding event. A positive rate means longs pay shorts.
+func FundingPayment(size, rate, markPrice float64) float64 {
+	return -size * rate * markPrice
+}
+
+type FundingEstimate struct {
+	Instrument string  `json:"instrument"`
+	Events     int     `json:"events"`
+	Estimated  float64 `json:"estimated"`
+	Actual     float64 `json:"actual"`
+	AvgSize    float64 `json:"avgSize"`
+	AvgRate    float64 `json:"avgRate"`
+}
+
+// EstimateFunding applies each funding rate in [from, to) to the position
+// size held at the time of the rate.
+func EstimateFunding(instrument string, currentSize float64, fills []intx.Fill, rates []intx.HistoricalFundingRate, from, to time.Time) (*FundingEstimate, error) {
+	estimate := &FundingEstimate{Instrument: instrument}
+
+	var totalSize, totalRate float64
+	for _, r := range rates {
+		eventTime, err := time.Parse(time.RFC3339Nano, r.EventTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid funding event time %s: %w", r.EventTime, err)
+		}
+		if !InTimeRange(eventTime, from, to) {
+			continue
+		}
+
+		size := PositionSizeAt(currentSize, fills, eventTime)
+		estimate.Estimated += FundingPayment(size, r.FundingRate, r.MarkPrice)
+		estimate.Events++
+		totalSize += size
+		totalRate += r.FundingRate
+	}
+
+	if estimate.Events > 0 {
+		estimate.AvgSize = totalSize / float64(estimate.Events)
+		estimate.AvgRate = totalRate / float64(estimate.Events)
+	}
+	return estimate, nil
+}
+
+type FundingStats struct {
+	Instrument          string  `json:"instrument"`
+	Count               int     `json:"count"`
+	Mean                float64 `json:"mean"`
+	Median              float64 `json:"median"`
+	Min                 float64 `json:"min"`
+	Max                 float64 `json:"max"`
+	StdDev              float64 `json:"stdDev"`
+	PeriodsPerYear      float64 `json:"periodsPerYear"`
+	AnnualizedMean      float64 `json:"annualizedMean"`
+	PredictedRate       float64 `json:"predictedRate"`
+	AnnualizedPredicted float64 `json:"annualizedPredicted"`
+}
+
+// ComputeFundingStats summarizes a rate history. The funding frequency is
+// inferred from the median spacing of events, defaulting to hourly.
+func ComputeFundingStats(instrument string, rates []intx.HistoricalFundingRate, predictedRate float64) *FundingStats {
+	stats := &FundingStats{Instrument: instrument, Count: len(rates), PredictedRate: predictedRate, PeriodsPerYear: hoursPerYear}
+
+	if len(rates) > 0 {
+		values := make([]float64, len(rates))
+		times := make([]time.Time, 0, len(rates))
+		for i, r := range rates {
+			values[i] = r.FundingRate
+			stats.Mean += r.FundingRate
+			if t, err := time.Parse(time.RFC3339Nano, r.EventTime); err == nil {
+				times = append(times, t)
+			}
+		}
+		stats.Mean /= float64(len(values))
+
+		sort.Float64s(values)
+		stats.Min = values[0]
+		stats.Max = values[len(values)-1]
+		stats.Median = median(values)
+
+		for _, v := range values {
+			stats.StdDev += (v - stats.Mean) * (v - stats.Mean)
+		}
+		stats.StdDev = math.Sqrt(stats.StdDev / float64(len(values)))
+
+		if interval := medianInterval(times); interval > 0 {
+			stats.PeriodsPerYear = float64(365*24*time.Hour) / float64(interval)
+		}
+	}
+
+	stats.AnnualizedMean = stats.Mean * stats.PeriodsPerYear
+	stats.AnnualizedPredicted = stats.PredictedRate * stats.PeriodsPerYear
+	return stats
+}
+
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func medianInterval(times []time.Time) time.Duration {
+	if len(times) < 2 {
+		return 0
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	intervals := make([]float64, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d > 0 {
+			intervals = append(intervals, float64(d))
+		}
+	}
+	sort.Float64s(intervals)
+	return time.Duration(median(intervals))
+}
+
+// FillsBySymbol groups fills per instrument symbol.
+func FillsBySymbol(fills []intx.Fill) map[string][]intx.Fill {
+	grouped := map[string][]intx.Fill{}
+	for _, f := range fills {
+		grouped[f.Symbol] = append(grouped[f.Symbol], f)
+	}
+	return grouped
+}
@@ -0,0 +1,332 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// JournalTimeLayout has a fixed width so that timestamps stored as text sort
+// chronologically in SQL.
+const JournalTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+const (
+	journalCursorFills     = "fills"
+	journalCursorTransfers = "transfers"
+)
+
+// As in snapshots, the typed columns are for ad-hoc SQL and the data column
+// holds the full API record.
+var journalSchema = []string{
+	`CREATE TABLE IF NOT EXISTS sync_state (portfolio_id TEXT NOT NULL, resource TEXT NOT NULL, cursor TEXT, synced_at TEXT NOT NULL, PRIMARY KEY (portfolio_id, resource))`,
+	`CREATE TABLE IF NOT EXISTS portfolios (portfolio_id TEXT PRIMARY KEY, name TEXT, synced_at TEXT NOT NULL, data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS orders (order_id TEXT PRIMARY KEY, portfolio_id TEXT NOT NULL, client_order_id TEXT, symbol TEXT, side TEXT, type TEXT, size TEXT, price TEXT, stop_price TEXT, status TEXT, exec_qty TEXT, avg_price TEXT, open INTEGER NOT NULL, synced_at TEXT NOT NULL, data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS fills (fill_id TEXT PRIMARY KEY, portfolio_id TEXT NOT NULL, order_id TEXT, client_order_id TEXT, symbol TEXT, side TEXT, qty TEXT, price TEXT, fee TEXT, fee_asset TEXT, event_time TEXT NOT NULL, data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS transfers (transfer_uuid TEXT PRIMARY KEY, type TEXT, status TEXT, asset TEXT, amount REAL, from_portfolio TEXT, to_portfolio TEXT, network TEXT, instrument_id INTEGER, created_at TEXT NOT NULL, updated_at TEXT, data TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS positions (portfolio_id TEXT NOT NULL, symbol TEXT NOT NULL, net_size TEXT, vwap TEXT, mark_price TEXT, unrealized_pnl TEXT, synced_at TEXT NOT NULL, data TEXT NOT NULL, PRIMARY KEY (portfolio_id, symbol))`,
+	`CREATE TABLE IF NOT EXISTS balances (portfolio_id TEXT NOT NULL, asset TEXT NOT NULL, quantity TEXT, hold TEXT, max_withdraw_amount TEXT, synced_at TEXT NOT NULL, data TEXT NOT NULL, PRIMARY KEY (portfolio_id, asset))`,
+	`CREATE INDEX IF NOT EXISTS fills_portfolio_time ON fills (portfolio_id, event_time)`,
+	`CREATE INDEX IF NOT EXISTS orders_portfolio ON orders (portfolio_id, open)`,
+	`CREATE INDEX IF NOT EXISTS transfers_created ON transfers (created_at)`,
+}
+
+// JournalSyncStats counts the records written for one portfolio.
+type JournalSyncStats struct {
+	PortfolioId string `json:"portfolioId"`
+	Name        string `json:"name"`
+	Orders      int    `json:"orders"`
+	Fills       int    `json:"fills"`
+	Transfers   int    `json:"transfers"`
+	Positions   int    `json:"positions"`
+	Balances    int    `json:"balances"`
+}
+
+func GetJournalPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return GetCliHomePath("journal.db")
+}
+
+// OpenJournal opens the journal, creating it and its tables if needed.
+func OpenJournal(path string) (*sql.DB, error) {
+	return OpenSqlite(path, journalSchema...)
+}
+
+// OpenJournalReadOnly opens an existing journal for queries, so that ad-hoc
+// SQL cannot modify it.
+func OpenJournalReadOnly(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no journal at %s: run sync first", path)
+	}
+	return OpenSqlite("file:" + path + "?mode=ro")
+}
+
+// SyncJournal mirrors one portfolio into the journal. Fills and transfers are
+// fetched from the stored cursors on, orders that were open at the last sync
+// or appear in new fills are refreshed, and positions and balances are
+// replaced by their current values.
+func SyncJournal(db *sql.DB, client *intx.Client, portfolio *intx.Portfolio) (*JournalSyncStats, error) {
+	portfolioId := portfolio.PortfolioId
+	stats := &JournalSyncStats{PortfolioId: portfolioId, Name: portfolio.Name}
+
+	state, err := FetchPortfolioState(client, portfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	fillsCursor, err := getJournalCursor(db, portfolioId, journalCursorFills)
+	if err != nil {
+		return nil, err
+	}
+	fills, err := ListAllFills(client, portfolioId, fillsCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	transfersCursor, err := getJournalCursor(db, portfolioId, journalCursorTransfers)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := ListAllTransfers(client, TransferFilter{PortfolioIds: portfolioId, TimeFrom: transfersCursor})
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := refreshJournalOrders(db, client, portfolioId, state.OpenOrders, fills)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format(JournalTimeLayout)
+	err = execInTx(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO portfolios VALUES (?, ?, ?, ?)`,
+			portfolioId, portfolio.Name, now, mustJson(portfolio)); err != nil {
+			return fmt.Errorf("cannot write portfolio: %w", err)
+		}
+
+		open := map[string]bool{}
+		for _, o := range state.OpenOrders {
+			open[o.OrderId] = true
+		}
+		for _, o := range orders {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO orders VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				o.OrderId, firstNonEmpty(o.PortfolioId, portfolioId), o.ClientOrderId, o.Symbol, o.Side, o.Type, o.Size, o.Price, o.StopPrice,
+				o.OrderStatus, o.ExecQty, o.AvgPrice, open[o.OrderId], now, mustJson(o)); err != nil {
+				return fmt.Errorf("cannot write order %s: %w", o.OrderId, err)
+			}
+		}
+		stats.Orders = len(orders)
+
+		latestFill := fillsCursor
+		for _, f := range fills {
+			t, err := ParseTime(f.EventTime)
+			if err != nil {
+				return fmt.Errorf("fill %s: %w", f.FillId, err)
+			}
+			eventTime := t.UTC().Format(JournalTimeLayout)
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO fills VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				f.FillId, firstNonEmpty(f.PortfolioId, portfolioId), f.OrderId, f.ClientOrderId, f.Symbol, f.Side, f.FillQty, f.FillPrice,
+				f.Fee, f.FeeAsset, eventTime, mustJson(f)); err != nil {
+				return fmt.Errorf("cannot write fill %s: %w", f.FillId, err)
+			}
+			if eventTime > latestFill {
+				latestFill = eventTime
+			}
+		}
+		stats.Fills = len(fills)
+
+		// Transfers are fetched again from the oldest one that can still
+		// change status, so that pending transfers pick up their final state.
+		latestTransfer, oldestPending := transfersCursor, ""
+		for _, tr := range transfers {
+			t, err := ParseTime(tr.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("transfer %s: %w", tr.TransferUuid, err)
+			}
+			createdAt := t.UTC().Format(JournalTimeLayout)
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO transfers VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				tr.TransferUuid, tr.Type, tr.Status, tr.Asset, tr.Amount,
+				firstNonEmpty(tr.FromPortfolio.Id, tr.FromPortfolio.Name), firstNonEmpty(tr.ToPortfolio.Id, tr.ToPortfolio.Name),
+				tr.NetworkName, tr.InstrumentId, createdAt, tr.UpdatedAt, mustJson(tr)); err != nil {
+				return fmt.Errorf("cannot write transfer %s: %w", tr.TransferUuid, err)
+			}
+			if createdAt > latestTransfer {
+				latestTransfer = createdAt
+			}
+			if !IsTransferFinal(tr.Status) && (oldestPending == "" || createdAt < oldestPending) {
+				oldestPending = createdAt
+			}
+		}
+		stats.Transfers = len(transfers)
+		if oldestPending != "" {
+			latestTransfer = oldestPending
+		}
+
+		if _, err := tx.Exec(`DELETE FROM positions WHERE portfolio_id = ?`, portfolioId); err != nil {
+			return fmt.Errorf("cannot clear positions: %w", err)
+		}
+		for _, p := range state.Positions {
+			if _, err := tx.Exec(`INSERT INTO positions VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				portfolioId, p.Symbol, p.NetSize, p.Vwap, p.MarkPrice, p.UnrealizedPnl, now, mustJson(p)); err != nil {
+				return fmt.Errorf("cannot write position %s: %w", p.Symbol, err)
+			}
+		}
+		stats.Positions = len(state.Positions)
+
+		if _, err := tx.Exec(`DELETE FROM balances WHERE portfolio_id = ?`, portfolioId); err != nil {
+			return fmt.Errorf("cannot clear balances: %w", err)
+		}
+		for _, b := range state.Balances {
+			if _, err := tx.Exec(`INSERT INTO balances VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				portfolioId, firstNonEmpty(b.AssetName, b.AssetId), b.Quantity, b.Hold, b.MaxWithdrawAmount, now, mustJson(b)); err != nil {
+				return fmt.Errorf("cannot write balance: %w", err)
+			}
+		}
+		stats.Balances = len(state.Balances)
+
+		if err := setJournalCursor(tx, portfolioId, journalCursorFills, latestFill, now); err != nil {
+			return err
+		}
+		return setJournalCursor(tx, portfolioId, journalCursorTransfers, latestTransfer, now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("portfolio %s: %w", portfolioId, err)
+	}
+	return stats, nil
+}
+
+// refreshJournalOrders returns the open orders together with the current
+// state of orders that were open at the last sync and of orders referenced by
+// fills but not yet in the journal, such as immediately filled market orders.
+func refreshJournalOrders(db *sql.DB, client *intx.Client, portfolioId string, openOrders []intx.Order, fills []intx.Fill) ([]intx.Order, error) {
+	orders := append([]intx.Order{}, openOrders...)
+	known := map[string]bool{}
+	for _, o := range openOrders {
+		known[o.OrderId] = true
+	}
+
+	var refresh []string
+	rows, err := db.Query(`SELECT order_id FROM orders WHERE portfolio_id = ? AND open = 1`, portfolioId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read orders: %w", err)
+	}
+	for rows.Next() {
+		var orderId string
+		if err := rows.Scan(&orderId); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("cannot read orders: %w", err)
+		}
+		if !known[orderId] {
+			known[orderId] = true
+			refresh = append(refresh, orderId)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read orders: %w", err)
+	}
+
+	for _, f := range fills {
+		if f.OrderId == "" || known[f.OrderId] {
+			continue
+		}
+		known[f.OrderId] = true
+
+		var exists bool
+		if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM orders WHERE order_id = ?)`, f.OrderId).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("cannot read orders: %w", err)
+		}
+		if !exists {
+			refresh = append(refresh, f.OrderId)
+		}
+	}
+
+	for _, orderId := range refresh {
+		ctx, cancel := GetContextWithTimeout()
+		response, err := client.GetOrderDetails(ctx, &intx.GetOrderDetailsRequest{PortfolioId: portfolioId, OrderId: orderId})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get order %s: %w", orderId, err)
+		}
+		if response.Order != nil {
+			orders = append(orders, *response.Order)
+		}
+	}
+	return orders, nil
+}
+
+func getJournalCursor(db *sql.DB, portfolioId, resource string) (string, error) {
+	var cursor sql.NullString
+	err := db.QueryRow(`SELECT cursor FROM sync_state WHERE portfolio_id = ? AND resource = ?`, portfolioId, resource).Scan(&cursor)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("cannot read sync state: %w", err)
+	}
+	return cursor.String, nil
+}
+
+func setJournalCursor(tx *sql.Tx, portfolioId, resource, cursor, syncedAt string) error {
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO sync_state VALUES (?, ?, ?, ?)`, portfolioId, resource, cursor, syncedAt); err != nil {
+		return fmt.Errorf("cannot write sync state: %w", err)
+	}
+	return nil
+}
+
+// QueryResult holds the rows of an ad-hoc query. Values are strings, numbers
+// or nil.
+type QueryResult struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+func QueryJournal(db *sql.DB, query string, args ...interface{}) (*QueryResult, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, rows.Err()
+}
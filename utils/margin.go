@@ -0,0 +1,248 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// DefaultMaintenanceRatio is the maintenance to initial margin ratio used when
+// the portfolio summary does not report both requirements.
+const DefaultMaintenanceRatio = 0.5
+
+type MarginPosition struct {
+	Instrument        string  `json:"instrument"`
+	Size              float64 `json:"size"`
+	MarkPrice         float64 `json:"markPrice"`
+	Notional          float64 `json:"notional"`
+	Imf               float64 `json:"imf"`
+	InitialMargin     float64 `json:"initialMargin"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+	LiquidationPrice  float64 `json:"liquidationPrice,omitempty"`
+}
+
+// MarginState is the margin position of a portfolio. Margin ratio is
+// maintenance margin as a fraction of collateral; the portfolio is liquidated
+// when it reaches 1.
+type MarginState struct {
+	Collateral        float64           `json:"collateral"`
+	InitialMargin     float64           `json:"initialMargin"`
+	MaintenanceMargin float64           `json:"maintenanceMargin"`
+	MarginRatio       float64           `json:"marginRatio"`
+	MarginUsage       float64           `json:"marginUsage"`
+	ExcessCollateral  float64           `json:"excessCollateral"`
+	InLiquidation     bool              `json:"inLiquidation"`
+	Positions         []*MarginPosition `json:"positions,omitempty"`
+}
+
+// MarginModel estimates requirements from the base initial margin fraction of
+// each instrument, floored by the portfolio margin override. Calibration
+// scales the model so that it reproduces the requirement reported by the
+// exchange for the current positions.
+type MarginModel struct {
+	BaseImf          map[string]float64 `json:"baseImf"`
+	MarginOverride   float64            `json:"marginOverride"`
+	MaintenanceRatio float64            `json:"maintenanceRatio"`
+	Calibration      float64            `json:"calibration"`
+}
+
+func NewMarginModel(summary *intx.Summary, instruments []*intx.Instrument) *MarginModel {
+	model := &MarginModel{
+		BaseImf:          map[string]float64{},
+		MarginOverride:   summary.MarginOverride,
+		MaintenanceRatio: DefaultMaintenanceRatio,
+		Calibration:      1,
+	}
+	for _, i := range instruments {
+		model.BaseImf[i.Symbol] = i.BaseImf
+	}
+	if summary.PortfolioInitialMarginNotional > 0 && summary.PortfolioMaintenanceMarginNotional > 0 {
+		model.MaintenanceRatio = summary.PortfolioMaintenanceMarginNotional / summary.PortfolioInitialMarginNotional
+	}
+	return model
+}
+
+// ReportedMarginState is the margin state as reported by the exchange.
+func ReportedMarginState(summary *intx.Summary) *MarginState {
+	collateral := ParseAmountOrZero(summary.Collateral)
+	return &MarginState{
+		Collateral:        collateral,
+		InitialMargin:     summary.PortfolioInitialMarginNotional,
+		MaintenanceMargin: summary.PortfolioMaintenanceMarginNotional,
+		MarginRatio:       ratio(summary.PortfolioMaintenanceMarginNotional, collateral),
+		MarginUsage:       ratio(summary.PortfolioInitialMarginNotional, collateral),
+		ExcessCollateral:  collateral - summary.PortfolioInitialMarginNotional,
+		InLiquidation:     summary.InLiquidation,
+	}
+}
+
+func (m *MarginModel) Imf(instrument string) float64 {
+	return math.Max(m.BaseImf[instrument], m.MarginOverride) * m.Calibration
+}
+
+// Calibrate sets the calibration so that positions require initialMargin.
+func (m *MarginModel) Calibrate(positions []*MarginPosition, initialMargin float64) {
+	m.Calibration = 1
+	modelled := m.Compute(0, positions).InitialMargin
+	if modelled > 0 && initialMargin > 0 {
+		m.Calibration = initialMargin / modelled
+	}
+}
+
+// Compute derives requirements for positions and, holding all other prices
+// constant, the mark price of each position at which collateral would fall to
+// the maintenance requirement.
+func (m *MarginModel) Compute(collateral float64, positions []*MarginPosition) *MarginState {
+	state := &MarginState{Collateral: collateral}
+	for _, p := range positions {
+		q := *p
+		q.Notional = q.Size * q.MarkPrice
+		q.Imf = m.Imf(q.Instrument)
+		q.InitialMargin = math.Abs(q.Notional) * q.Imf
+		q.MaintenanceMargin = q.InitialMargin * m.MaintenanceRatio
+		state.InitialMargin += q.InitialMargin
+		state.MaintenanceMargin += q.MaintenanceMargin
+		state.Positions = append(state.Positions, &q)
+	}
+
+	state.MarginRatio = ratio(state.MaintenanceMargin, collateral)
+	state.MarginUsage = ratio(state.InitialMargin, collateral)
+	state.ExcessCollateral = collateral - state.InitialMargin
+	state.InLiquidation = state.MaintenanceMargin > 0 && collateral <= state.MaintenanceMargin
+
+	for _, p := range state.Positions {
+		p.LiquidationPrice = m.liquidationPrice(state, p)
+	}
+	return state
+}
+
+// liquidationPrice solves C + s(P - mark) = mmf|s|P + MM_others for P. Zero
+// means the position alone cannot bring the portfolio to liquidation.
+func (m *MarginModel) liquidationPrice(state *MarginState, p *MarginPosition) float64 {
+	if p.Size == 0 {
+		return 0
+	}
+	mmf := p.Imf * m.MaintenanceRatio
+	others := state.MaintenanceMargin - p.MaintenanceMargin
+
+	denominator := p.Size - mmf*math.Abs(p.Size)
+	if denominator == 0 {
+		return 0
+	}
+	price := (others - state.Collateral + p.Size*p.MarkPrice) / denominator
+	if price <= 0 {
+		return 0
+	}
+	return price
+}
+
+func MarginPositions(positions []intx.Position) []*MarginPosition {
+	var result []*MarginPosition
+	for _, p := range positions {
+		size := ParseAmountOrZero(p.NetSize)
+		if size == 0 {
+			continue
+		}
+		result = append(result, &MarginPosition{
+			Instrument: p.Symbol,
+			Size:       size,
+			MarkPrice:  ParseAmountOrZero(p.MarkPrice),
+		})
+	}
+	return result
+}
+
+// ParsePositionChanges parses a comma separated list of INSTRUMENT:SIZE
+// changes, for example BTC-PERP:+2,ETH-PERP:-10.
+func ParsePositionChanges(value string) (map[string]float64, error) {
+	changes := map[string]float64{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		instrument, size, ok := strings.Cut(item, ":")
+		if !ok || instrument == "" {
+			return nil, fmt.Errorf("invalid position change %s: expected INSTRUMENT:SIZE", item)
+		}
+		f, err := ParseAmount(strings.TrimPrefix(size, "+"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid position change %s: %w", item, err)
+		}
+		changes[strings.ToUpper(instrument)] += f
+	}
+	return changes, nil
+}
+
+// ParsePercent parses values such as -10% into fractions such as -0.1.
+func ParsePercent(value string) (float64, error) {
+	f, err := ParseAmount(strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(value), "%"), "+"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %s: %w", value, err)
+	}
+	return f / 100, nil
+}
+
+// ApplyWhatIf returns the collateral and positions after trading changes at
+// the current mark prices and then moving every mark price by shock. marks
+// supplies prices for instruments without a position.
+func ApplyWhatIf(collateral float64, positions []*MarginPosition, changes map[string]float64, shock float64, marks map[string]float64) (float64, []*MarginPosition, error) {
+	bySymbol := map[string]*MarginPosition{}
+	var result []*MarginPosition
+	for _, p := range positions {
+		q := *p
+		bySymbol[q.Instrument] = &q
+		result = append(result, &q)
+	}
+
+	var added []string
+	for instrument := range changes {
+		if _, ok := bySymbol[instrument]; !ok {
+			added = append(added, instrument)
+		}
+	}
+	sort.Strings(added)
+	for _, instrument := range added {
+		mark, ok := marks[instrument]
+		if !ok || mark <= 0 {
+			return 0, nil, fmt.Errorf("no mark price for %s", instrument)
+		}
+		p := &MarginPosition{Instrument: instrument, MarkPrice: mark}
+		bySymbol[instrument] = p
+		result = append(result, p)
+	}
+
+	for instrument, size := range changes {
+		bySymbol[instrument].Size += size
+	}
+
+	var kept []*MarginPosition
+	for _, p := range result {
+		shocked := p.MarkPrice * (1 + shock)
+		collateral += p.Size * (shocked - p.MarkPrice)
+		p.MarkPrice = shocked
+		if p.Size != 0 {
+			kept = append(kept, p)
+		}
+	}
+	return collateral, kept, nil
+}
@@ -0,0 +1,100 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"fmt"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// GetInstrumentSymbols maps numeric instrument IDs, as used by transfers, to
+// instrument symbols such as BTC-PERP.
+func GetInstrumentSymbols(client *intx.Client) (map[string]string, error) {
+	instruments, err := ListInstruments(client)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := map[string]string{}
+	for _, i := range instruments {
+		symbols[i.InstrumentId] = i.Symbol
+	}
+	return symbols, nil
+}
+
+func ListInstruments(client *intx.Client) ([]*intx.Instrument, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.ListInstruments(ctx, &intx.ListInstrumentsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list instruments: %w", err)
+	}
+	return response.Instruments, nil
+}
+
+func GetInstrument(client *intx.Client, instrumentId string) (*intx.Instrument, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.GetInstrument(ctx, &intx.GetInstrumentRequest{InstrumentId: instrumentId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get instrument %s: %w", instrumentId, err)
+	}
+	if response.InstrumentDetail == nil {
+		return nil, fmt.Errorf("no details returned for %s", instrumentId)
+	}
+	return response.InstrumentDetail, nil
+}
+
+func GetQuote(client *intx.Client, instrumentId string) (*intx.Quote, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.GetInstrumentQuote(ctx, &intx.GetInstrumentQuoteRequest{InstrumentId: instrumentId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get quote for %s: %w", instrumentId, err)
+	}
+	if response.InstrumentQuote == nil {
+		return nil, fmt.Errorf("no quote returned for %s", instrumentId)
+	}
+	return response.InstrumentQuote, nil
+}
+
+// GetMarkPrices returns the current mark price of each instrument.
+func GetMarkPrices(client *intx.Client, instrumentIds []string) (map[string]float64, error) {
+	marks := map[string]float64{}
+	for _, id := range instrumentIds {
+		quote, err := GetQuote(client, id)
+		if err != nil {
+			return nil, err
+		}
+		marks[id] = ParseAmountOrZero(quote.MarkPrice)
+	}
+	return marks, nil
+}
+
+func GetPositions(client *intx.Client, portfolioId string) ([]intx.Position, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.GetPortfolioPositions(ctx, &intx.GetPortfolioPositionsRequest{PortfolioId: portfolioId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get positions for %s: %w", portfolioId, err)
+	}
+	return response.Positions, nil
+}
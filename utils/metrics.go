@@ -0,0 +1,293 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MetricTypeGauge     = "gauge"
+	MetricTypeCounter   = "counter"
+	MetricTypeHistogram = "histogram"
+)
+
+var apiLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// apiPathWords are the static segments of API paths. Other segments are IDs
+// and are replaced in metric labels to bound their cardinality.
+var apiPathWords = map[string]bool{
+	"api": true, "v1": true, "portfolios": true, "instruments": true, "quote": true, "funding": true,
+	"summary": true, "balances": true, "positions": true, "fills": true, "transfers": true, "orders": true,
+	"assets": true, "networks": true, "address": true, "withdraw": true, "counterparty": true,
+	"counterparty-id": true, "validate-counterparty-id": true, "margin-override": true, "detail": true,
+}
+
+// MetricFamily is a metric and its samples in the Prometheus text format.
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []*MetricSample
+}
+
+// MetricSample is one value of a family. Labels alternate names and values;
+// Suffix is appended to the family name, e.g. _bucket for histograms.
+type MetricSample struct {
+	Suffix string
+	Labels []string
+	Value  float64
+}
+
+// MetricSet collects metric families in the order they are first used.
+type MetricSet struct {
+	families []*MetricFamily
+	index    map[string]*MetricFamily
+}
+
+func NewMetricSet() *MetricSet {
+	return &MetricSet{index: map[string]*MetricFamily{}}
+}
+
+func (s *MetricSet) family(name, help, metricType string) *MetricFamily {
+	f, ok := s.index[name]
+	if !ok {
+		f = &MetricFamily{Name: name, Help: help, Type: metricType}
+		s.index[name] = f
+		s.families = append(s.families, f)
+	}
+	return f
+}
+
+func (s *MetricSet) Gauge(name, help string, value float64, labels ...string) {
+	f := s.family(name, help, MetricTypeGauge)
+	f.Samples = append(f.Samples, &MetricSample{Labels: labels, Value: value})
+}
+
+func (s *MetricSet) Counter(name, help string, value float64, labels ...string) {
+	f := s.family(name, help, MetricTypeCounter)
+	f.Samples = append(f.Samples, &MetricSample{Labels: labels, Value: value})
+}
+
+func (s *MetricSet) Families() []*MetricFamily {
+	return s.families
+}
+
+// WriteMetrics writes families in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer, families ...[]*MetricFamily) error {
+	out := bufio.NewWriter(w)
+	for _, list := range families {
+		for _, f := range list {
+			if len(f.Samples) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "# HELP %s %s\n", f.Name, escapeMetricHelp(f.Help))
+			fmt.Fprintf(out, "# TYPE %s %s\n", f.Name, f.Type)
+			for _, sample := range f.Samples {
+				out.WriteString(f.Name + sample.Suffix)
+				writeMetricLabels(out, sample.Labels)
+				out.WriteString(" " + formatMetricValue(sample.Value) + "\n")
+			}
+		}
+	}
+	return out.Flush()
+}
+
+func writeMetricLabels(out *bufio.Writer, labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+	out.WriteString("{")
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		fmt.Fprintf(out, "%s=\"%s\"", labels[i], escapeMetricLabel(labels[i+1]))
+	}
+	out.WriteString("}")
+}
+
+func escapeMetricLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func escapeMetricHelp(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(value)
+}
+
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// ApiMetrics counts API requests, errors and latency by method and path.
+type ApiMetrics struct {
+	mu        sync.Mutex
+	requests  map[[3]string]float64
+	errors    map[[2]string]float64
+	latencies map[[2]string]*latencyHistogram
+}
+
+type latencyHistogram struct {
+	counts []float64
+	count  float64
+	sum    float64
+}
+
+func NewApiMetrics() *ApiMetrics {
+	return &ApiMetrics{
+		requests:  map[[3]string]float64{},
+		errors:    map[[2]string]float64{},
+		latencies: map[[2]string]*latencyHistogram{},
+	}
+}
+
+// Transport wraps next, or the default transport when nil, to record every
+// request. Responses with status 400 or above count as errors.
+func (m *ApiMetrics) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		res, err := next.RoundTrip(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(res.StatusCode)
+		}
+		m.observe(req.Method, ApiPathLabel(req.URL.Path), code, time.Since(start), err != nil || res.StatusCode >= 400)
+		return res, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (m *ApiMetrics) observe(method, path, code string, elapsed time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[[3]string{method, path, code}]++
+	if failed {
+		m.errors[[2]string{method, path}]++
+	}
+
+	key := [2]string{method, path}
+	h, ok := m.latencies[key]
+	if !ok {
+		h = &latencyHistogram{counts: make([]float64, len(apiLatencyBuckets))}
+		m.latencies[key] = h
+	}
+	seconds := elapsed.Seconds()
+	for i, bound := range apiLatencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Families returns the current counters and latency histograms.
+func (m *ApiMetrics) Families() []*MetricFamily {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests := &MetricFamily{Name: "intx_api_requests_total", Help: "API requests by method, path and status code.", Type: MetricTypeCounter}
+	for _, key := range sortedKeys3(m.requests) {
+		requests.Samples = append(requests.Samples, &MetricSample{
+			Labels: []string{"method", key[0], "path", key[1], "code", key[2]},
+			Value:  m.requests[key],
+		})
+	}
+
+	errs := &MetricFamily{Name: "intx_api_errors_total", Help: "API requests that failed or returned status 400 or above.", Type: MetricTypeCounter}
+	for _, key := range sortedKeys2(m.errors) {
+		errs.Samples = append(errs.Samples, &MetricSample{Labels: []string{"method", key[0], "path", key[1]}, Value: m.errors[key]})
+	}
+
+	latency := &MetricFamily{Name: "intx_api_request_duration_seconds", Help: "API request latency.", Type: MetricTypeHistogram}
+	for _, key := range sortedKeys2(m.latencies) {
+		h := m.latencies[key]
+		for i, bound := range apiLatencyBuckets {
+			latency.Samples = append(latency.Samples, &MetricSample{
+				Suffix: "_bucket",
+				Labels: []string{"method", key[0], "path", key[1], "le", formatMetricValue(bound)},
+				Value:  h.counts[i],
+			})
+		}
+		latency.Samples = append(latency.Samples,
+			&MetricSample{Suffix: "_bucket", Labels: []string{"method", key[0], "path", key[1], "le", "+Inf"}, Value: h.count},
+			&MetricSample{Suffix: "_sum", Labels: []string{"method", key[0], "path", key[1]}, Value: h.sum},
+			&MetricSample{Suffix: "_count", Labels: []string{"method", key[0], "path", key[1]}, Value: h.count},
+		)
+	}
+
+	return []*MetricFamily{requests, errs, latency}
+}
+
+// ApiPathLabel replaces the ID segments of an API path with :id.
+func ApiPathLabel(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if !apiPathWords[s] {
+			segments[i] = ":id"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func sortedKeys2[V any](m map[[2]string]V) [][2]string {
+	keys := make([][2]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0]+"\x00"+keys[i][1] < keys[j][0]+"\x00"+keys[j][1]
+	})
+	return keys
+}
+
+func sortedKeys3(m map[[3]string]float64) [][3]string {
+	keys := make([][3]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.Join(keys[i][:], "\x00") < strings.Join(keys[j][:], "\x00")
+	})
+	return keys
+}
@@ -0,0 +1,59 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseAmount parses a decimal string as returned by the INTX API. Blank
+// values parse as zero.
+func ParseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %s", value)
+	}
+	return f, nil
+}
+
+// ParseAmountOrZero is ParseAmount for display paths where bad values are
+// treated as zero.
+func ParseAmountOrZero(value string) float64 {
+	f, _ := ParseAmount(value)
+	return f
+}
+
+func FormatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// FormatRounded formats value rounded to at most places decimals, without
+// trailing zeros.
+func FormatRounded(value float64, places int) string {
+	scale := math.Pow10(places)
+	rounded := math.Round(value*scale) / scale
+	if rounded == 0 {
+		rounded = 0 // normalize negative zero
+	}
+	return FormatAmount(rounded)
+}
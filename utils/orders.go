@@ -0,0 +1,142 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	SideBuy  = "BUY"
+	SideSell = "SELL"
+
+	OrderTypeMarket    = "MARKET"
+	OrderTypeLimit     = "LIMIT"
+	OrderTypeStop      = "STOP"
+	OrderTypeStopLimit = "STOP_LIMIT"
+
+	OrderStatusFilled    = "FILLED"
+	OrderStatusCancelled = "CANCELLED"
+	OrderStatusRejected  = "REJECTED"
+	OrderStatusExpired   = "EXPIRED"
+)
+
+// IsOrderFinal reports whether an order status can no longer change.
+func IsOrderFinal(status string) bool {
+	switch strings.ToUpper(status) {
+	case OrderStatusFilled, OrderStatusCancelled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+func GetOrder(client *intx.Client, portfolioId, orderId string) (*intx.Order, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.GetOrderDetails(ctx, &intx.GetOrderDetailsRequest{PortfolioId: portfolioId, OrderId: orderId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get order %s: %w", orderId, err)
+	}
+	if response.Order == nil {
+		return nil, fmt.Errorf("no details returned for order %s", orderId)
+	}
+	return response.Order, nil
+}
+
+// ValidateCreateOrderRequest normalizes and checks an order before it is sent
+// so that obvious mistakes fail locally rather than at the exchange.
+func ValidateCreateOrderRequest(request *intx.CreateOrderRequest) error {
+	request.Side = strings.ToUpper(request.Side)
+	request.Type = strings.ToUpper(request.Type)
+	request.Tif = strings.ToUpper(request.Tif)
+
+	if request.InstrumentId == "" {
+		return errors.New("instrument ID is required")
+	}
+	if request.Side != SideBuy && request.Side != SideSell {
+		return fmt.Errorf("invalid side %s: must be BUY or SELL", request.Side)
+	}
+	if request.Type == "" {
+		return errors.New("order type is required")
+	}
+
+	if err := requirePositive("size", request.Size); err != nil {
+		return err
+	}
+
+	switch request.Type {
+	case OrderTypeLimit, OrderTypeStopLimit:
+		if err := requirePositive("limit price", request.Price); err != nil {
+			return err
+		}
+	case OrderTypeMarket, OrderTypeStop:
+		if request.Price != "" {
+			return fmt.Errorf("limit price cannot be set on %s orders", request.Type)
+		}
+	}
+
+	switch request.Type {
+	case OrderTypeStop, OrderTypeStopLimit:
+		if request.StopPrice == nil {
+			return fmt.Errorf("stop price is required for %s orders", request.Type)
+		}
+		if err := requirePositive("stop price", *request.StopPrice); err != nil {
+			return err
+		}
+	}
+
+	if request.PostOnly != nil && *request.PostOnly && request.Type != OrderTypeLimit {
+		return errors.New("post only is only supported for LIMIT orders")
+	}
+	return nil
+}
+
+func requirePositive(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	f, err := ParseAmount(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if f <= 0 {
+		return fmt.Errorf("%s must be positive", name)
+	}
+	return nil
+}
+
+// ValidateModifyOrderRequest applies the create-order checks to order as it
+// would look after the modification.
+func ValidateModifyOrderRequest(order intx.Order, request *intx.ModifyOrderRequest) error {
+	modified := &intx.CreateOrderRequest{
+		Side:         order.Side,
+		Size:         firstNonEmpty(request.Size, order.Size),
+		Tif:          order.Tif,
+		InstrumentId: firstNonEmpty(order.InstrumentId, order.Symbol),
+		Type:         order.Type,
+		Price:        firstNonEmpty(request.Price, order.Price),
+		StopPrice:    StringPtr(firstNonEmpty(request.StopPrice, order.StopPrice)),
+	}
+	if request.Size == "" && request.Price == "" && request.StopPrice == "" {
+		return errors.New("nothing to modify")
+	}
+	return ValidateCreateOrderRequest(modified)
+}
@@ -0,0 +1,118 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+type PortfolioOverview struct {
+	PortfolioId       string  `json:"portfolioId"`
+	Name              string  `json:"name"`
+	Collateral        float64 `json:"collateral"`
+	UnrealizedPnl     float64 `json:"unrealizedPnl"`
+	InitialMargin     float64 `json:"initialMargin"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+	MarginUsage       float64 `json:"marginUsage"`
+	PositionNotional  float64 `json:"positionNotional"`
+	OpenOrders        int     `json:"openOrders"`
+	InLiquidation     bool    `json:"inLiquidation"`
+	Error             string  `json:"error,omitempty"`
+}
+
+type InstrumentExposure struct {
+	Instrument    string  `json:"instrument"`
+	NetSize       float64 `json:"netSize"`
+	NetNotional   float64 `json:"netNotional"`
+	GrossNotional float64 `json:"grossNotional"`
+	Portfolios    int     `json:"portfolios"`
+}
+
+type Overview struct {
+	Portfolios []*PortfolioOverview  `json:"portfolios"`
+	Total      *PortfolioOverview    `json:"total"`
+	Exposures  []*InstrumentExposure `json:"exposures"`
+	NetDelta   float64               `json:"netDelta"`
+}
+
+// ComputeOverview consolidates portfolio states. Margin usage is initial
+// margin as a fraction of collateral; net delta is the signed notional of all
+// positions.
+func ComputeOverview(states []*PortfolioState) *Overview {
+	overview := &Overview{Total: &PortfolioOverview{PortfolioId: "TOTAL"}}
+	exposures := map[string]*InstrumentExposure{}
+
+	for _, state := range states {
+		summary := state.Summary
+		o := &PortfolioOverview{
+			PortfolioId:       state.Portfolio.PortfolioId,
+			Name:              state.Portfolio.Name,
+			Collateral:        ParseAmountOrZero(summary.Collateral),
+			UnrealizedPnl:     ParseAmountOrZero(summary.UnrealizedPnl),
+			InitialMargin:     summary.PortfolioInitialMarginNotional,
+			MaintenanceMargin: summary.PortfolioMaintenanceMarginNotional,
+			OpenOrders:        len(state.OpenOrders),
+			InLiquidation:     summary.InLiquidation,
+		}
+		o.MarginUsage = ratio(o.InitialMargin, o.Collateral)
+
+		for _, p := range state.Positions {
+			size := ParseAmountOrZero(p.NetSize)
+			if size == 0 {
+				continue
+			}
+			notional := size * ParseAmountOrZero(p.MarkPrice)
+			o.PositionNotional += math.Abs(notional)
+
+			e, ok := exposures[p.Symbol]
+			if !ok {
+				e = &InstrumentExposure{Instrument: p.Symbol}
+				exposures[p.Symbol] = e
+			}
+			e.NetSize += size
+			e.NetNotional += notional
+			e.GrossNotional += math.Abs(notional)
+			e.Portfolios++
+			overview.NetDelta += notional
+		}
+
+		overview.Portfolios = append(overview.Portfolios, o)
+		overview.Total.Collateral += o.Collateral
+		overview.Total.UnrealizedPnl += o.UnrealizedPnl
+		overview.Total.InitialMargin += o.InitialMargin
+		overview.Total.MaintenanceMargin += o.MaintenanceMargin
+		overview.Total.PositionNotional += o.PositionNotional
+		overview.Total.OpenOrders += o.OpenOrders
+		overview.Total.InLiquidation = overview.Total.InLiquidation || o.InLiquidation
+	}
+	overview.Total.MarginUsage = ratio(overview.Total.InitialMargin, overview.Total.Collateral)
+
+	for _, e := range exposures {
+		overview.Exposures = append(overview.Exposures, e)
+	}
+	sort.Slice(overview.Exposures, func(i, j int) bool {
+		return overview.Exposures[i].Instrument < overview.Exposures[j].Instrument
+	})
+	return overview
+}
+
+func ratio(numerator, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
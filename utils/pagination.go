@@ -0,0 +1,128 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const PageSize = 100
+
+// ListAllFills pages through ListFillsByPortfolios, using a fresh request
+// timeout for every page. Fills that shift across pages while paging are
+// returned once.
+func ListAllFills(client *intx.Client, portfolioIds, timeFrom string) ([]intx.Fill, error) {
+	var fills []intx.Fill
+	seen := map[string]bool{}
+	for offset := 0; ; offset += PageSize {
+		ctx, cancel := GetContextWithTimeout()
+		response, err := client.ListFillsByPortfolios(ctx, &intx.ListFillsByPortfoliosRequest{
+			PortfolioIds: portfolioIds,
+			TimeFrom:     timeFrom,
+			Pagination:   &intx.PaginationParams{ResultLimit: PageSize, ResultOffset: offset},
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("cannot list fills at offset %d: %w", offset, err)
+		}
+
+		for _, f := range response.Results {
+			if !seen[f.FillId] {
+				seen[f.FillId] = true
+				fills = append(fills, f)
+			}
+		}
+		if len(response.Results) < PageSize {
+			return fills, nil
+		}
+	}
+}
+
+// TransferFilter holds the filters of ListAllTransfers.
+type TransferFilter struct {
+	PortfolioIds string
+	TimeFrom     string
+	TimeTo       string
+	Status       string
+	Type         string
+}
+
+type listTransfersResponse struct {
+	Pagination intx.PaginationSubset `json:"pagination"`
+	Results    []Transfer            `json:"results"`
+}
+
+// ListAllTransfers pages through the transfers matching filter. The SDK
+// cannot decode the list response, so the endpoint is called directly.
+func ListAllTransfers(client *intx.Client, filter TransferFilter) ([]Transfer, error) {
+	query := url.Values{}
+	query.Set("portfolios", filter.PortfolioIds)
+	for key, value := range map[string]string{
+		"time_from": filter.TimeFrom,
+		"time_to":   filter.TimeTo,
+		"status":    filter.Status,
+		"type":      filter.Type,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	var transfers []Transfer
+	for offset := 0; ; offset += PageSize {
+		query.Set("result_limit", strconv.Itoa(PageSize))
+		query.Set("result_offset", strconv.Itoa(offset))
+
+		ctx, cancel := GetContextWithTimeout()
+		response := &listTransfersResponse{}
+		err := CallApi(ctx, client, "GET", "/transfers", query, nil, response)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("cannot list transfers at offset %d: %w", offset, err)
+		}
+
+		transfers = append(transfers, response.Results...)
+		if len(response.Results) < PageSize {
+			return transfers, nil
+		}
+	}
+}
+
+// ListAllOpenOrders pages through the open orders of a portfolio.
+func ListAllOpenOrders(client *intx.Client, portfolioId, instrumentId string) ([]intx.Order, error) {
+	var orders []intx.Order
+	for offset := 0; ; offset += PageSize {
+		ctx, cancel := GetContextWithTimeout()
+		response, err := client.ListOpenOrders(ctx, &intx.ListOpenOrdersRequest{
+			PortfolioId:  portfolioId,
+			InstrumentId: instrumentId,
+			Pagination:   &intx.PaginationParams{ResultLimit: PageSize, ResultOffset: offset},
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("cannot list open orders at offset %d: %w", offset, err)
+		}
+
+		orders = append(orders, response.Results...)
+		if len(response.Results) < PageSize {
+			return orders, nil
+		}
+	}
+}
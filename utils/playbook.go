@@ -0,0 +1,308 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultPlaybookItemName = "item"
+
+var playbookNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Playbook is a sequence of intxctl commands. Commands, conditions and loop
+// lists are Go templates over .vars, the results of earlier steps as
+// .steps.NAME.output (parsed JSON output) and .steps.NAME.stdout, and the
+// current loop item.
+type Playbook struct {
+	Vars  map[string]interface{} `yaml:"vars"`
+	Steps []*PlaybookStep        `yaml:"steps"`
+}
+
+// PlaybookStep runs a command once, or once per item of Foreach, which is a
+// YAML list or a template rendering to a JSON array or to comma or newline
+// separated values. The step is skipped when When renders to an empty or
+// false value.
+type PlaybookStep struct {
+	Name            string      `yaml:"name"`
+	Run             string      `yaml:"run"`
+	When            string      `yaml:"when"`
+	Foreach         interface{} `yaml:"foreach"`
+	As              string      `yaml:"as"`
+	ContinueOnError bool        `yaml:"continueOnError"`
+	run             *template.Template
+	when            *template.Template
+	foreach         *template.Template
+}
+
+func LoadPlaybook(path string) (*Playbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read playbook: %w", err)
+	}
+
+	playbook := &Playbook{}
+	if err := yaml.Unmarshal(data, playbook); err != nil {
+		return nil, fmt.Errorf("cannot parse playbook: %w", err)
+	}
+	if err := playbook.init(); err != nil {
+		return nil, fmt.Errorf("invalid playbook: %w", err)
+	}
+	return playbook, nil
+}
+
+func (p *Playbook) init() error {
+	if p.Vars == nil {
+		p.Vars = map[string]interface{}{}
+	}
+	if len(p.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+
+	names := map[string]bool{}
+	for i, step := range p.Steps {
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step%d", i+1)
+		}
+		if !playbookNamePattern.MatchString(step.Name) {
+			return fmt.Errorf("step name %s must consist of letters, digits and underscores", step.Name)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step %s is listed more than once", step.Name)
+		}
+		names[step.Name] = true
+		if step.As == "" {
+			step.As = defaultPlaybookItemName
+		}
+		if step.As == "vars" || step.As == "steps" {
+			return fmt.Errorf("step %s: as cannot be %s", step.Name, step.As)
+		}
+		if err := step.parse(); err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+func (step *PlaybookStep) parse() error {
+	if strings.TrimSpace(step.Run) == "" {
+		return errors.New("run is required")
+	}
+
+	var err error
+	if step.run, err = parsePlaybookTemplate("run", step.Run); err != nil {
+		return err
+	}
+	if step.When != "" {
+		if step.when, err = parsePlaybookTemplate("when", step.When); err != nil {
+			return err
+		}
+	}
+	switch f := step.Foreach.(type) {
+	case nil, []interface{}:
+	case string:
+		if step.foreach, err = parsePlaybookTemplate("foreach", f); err != nil {
+			return err
+		}
+	default:
+		return errors.New("foreach must be a list or a template string")
+	}
+	return nil
+}
+
+func parsePlaybookTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(playbookFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return t, nil
+}
+
+var playbookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": func(sep string, values interface{}) string {
+		var parts []string
+		for _, v := range toList(values) {
+			parts = append(parts, fmt.Sprint(v))
+		}
+		return strings.Join(parts, sep)
+	},
+	"pluck": func(field string, values interface{}) []interface{} {
+		var plucked []interface{}
+		for _, v := range toList(values) {
+			if m, ok := v.(map[string]interface{}); ok {
+				plucked = append(plucked, m[field])
+			}
+		}
+		return plucked
+	},
+	"default": func(def, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return def
+		}
+		return value
+	},
+	"quote": func(v interface{}) string {
+		return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", `'\''`) + "'"
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+func toList(values interface{}) []interface{} {
+	switch v := values.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	case nil:
+		return nil
+	}
+	return []interface{}{values}
+}
+
+// PlaybookStepResult is what later steps see of a step as .steps.NAME. For
+// loops, output and stdout are lists with one entry per item.
+type PlaybookStepResult map[string]interface{}
+
+// PlaybookContext is the template data of a playbook run.
+type PlaybookContext struct {
+	Vars  map[string]interface{}
+	Steps map[string]PlaybookStepResult
+}
+
+func NewPlaybookContext(p *Playbook, overrides map[string]string) *PlaybookContext {
+	vars := map[string]interface{}{}
+	for k, v := range p.Vars {
+		vars[k] = v
+	}
+	for k, v := range overrides {
+		vars[k] = v
+	}
+	return &PlaybookContext{Vars: vars, Steps: map[string]PlaybookStepResult{}}
+}
+
+func (c *PlaybookContext) data(step *PlaybookStep, item interface{}) map[string]interface{} {
+	steps := map[string]interface{}{}
+	for k, v := range c.Steps {
+		steps[k] = map[string]interface{}(v)
+	}
+	data := map[string]interface{}{"vars": c.Vars, "steps": steps}
+	if item != nil {
+		data[step.As] = item
+	}
+	return data
+}
+
+func (c *PlaybookContext) render(t *template.Template, step *PlaybookStep, item interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, c.data(step, item)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Enabled evaluates the condition of a step.
+func (c *PlaybookContext) Enabled(step *PlaybookStep) (bool, error) {
+	if step.when == nil {
+		return true, nil
+	}
+	value, err := c.render(step.when, step, nil)
+	if err != nil {
+		return false, fmt.Errorf("cannot evaluate when: %w", err)
+	}
+	switch strings.ToLower(value) {
+	case "", "false", "0", "no", "<no value>", "[]", "map[]", "null":
+		return false, nil
+	}
+	return true, nil
+}
+
+// Items returns the loop items of a step, or nil for a step without a loop.
+func (c *PlaybookContext) Items(step *PlaybookStep) ([]interface{}, error) {
+	if list, ok := step.Foreach.([]interface{}); ok {
+		return list, nil
+	}
+	if step.foreach == nil {
+		return nil, nil
+	}
+
+	value, err := c.render(step.foreach, step, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot evaluate foreach: %w", err)
+	}
+	if strings.HasPrefix(value, "[") {
+		var items []interface{}
+		if err := json.Unmarshal([]byte(value), &items); err != nil {
+			return nil, fmt.Errorf("foreach is not a JSON array: %w", err)
+		}
+		return items, nil
+	}
+
+	items := []interface{}{}
+	for _, s := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if s = strings.TrimSpace(s); s != "" {
+			items = append(items, s)
+		}
+	}
+	return items, nil
+}
+
+// Command renders the command line of a step for one item.
+func (c *PlaybookContext) Command(step *PlaybookStep, item interface{}) ([]string, error) {
+	line, err := c.render(step.run, step, item)
+	if err != nil {
+		return nil, fmt.Errorf("cannot render command: %w", err)
+	}
+	args, err := SplitCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 && args[0] == scheduledCommandName {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil, errors.New("command renders empty")
+	}
+	return args, nil
+}
+
+// ParseCommandOutput decodes the output of a command as JSON, or returns nil
+// when it is not JSON.
+func ParseCommandOutput(stdout string) interface{} {
+	var output interface{}
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		return nil
+	}
+	return output
+}
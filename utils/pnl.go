@@ -0,0 +1,319 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	PnlMethodFifo = "fifo"
+	PnlMethodLifo = "lifo"
+	PnlMethodAvg  = "avg"
+
+	sideBuy = "BUY"
+
+	TransferTypeFunding = "FUNDING"
+
+	quantityEpsilon = 1e-12
+)
+
+type lot struct {
+	size  float64 // signed: positive for long, negative for short
+	price float64
+}
+
+// LotBook matches fills of one instrument against open lots using FIFO, LIFO
+// or average cost accounting.
+type LotBook struct {
+	method string
+	lots   []lot
+}
+
+func NewLotBook(method string) (*LotBook, error) {
+	switch method {
+	case PnlMethodFifo, PnlMethodLifo, PnlMethodAvg:
+		return &LotBook{method: method}, nil
+	default:
+		return nil, fmt.Errorf("invalid method %s: must be fifo, lifo or avg", method)
+	}
+}
+
+// Apply books a fill of signed size at price and returns the realized PnL of
+// any lots it closed.
+func (b *LotBook) Apply(size, price float64) (realized float64) {
+	for math.Abs(size) > quantityEpsilon && len(b.lots) > 0 && !sameSign(b.lots[0].size, size) {
+		i := 0
+		if b.method == PnlMethodLifo {
+			i = len(b.lots) - 1
+		}
+
+		closed := math.Min(math.Abs(size), math.Abs(b.lots[i].size))
+		if b.lots[i].size > 0 {
+			realized += closed * (price - b.lots[i].price)
+			b.lots[i].size -= closed
+			size += closed
+		} else {
+			realized += closed * (b.lots[i].price - price)
+			b.lots[i].size += closed
+			size -= closed
+		}
+
+		if math.Abs(b.lots[i].size) <= quantityEpsilon {
+			b.lots = append(b.lots[:i], b.lots[i+1:]...)
+		}
+	}
+
+	if math.Abs(size) > quantityEpsilon {
+		if b.method == PnlMethodAvg && len(b.lots) == 1 {
+			total := b.lots[0].size + size
+			b.lots[0].price = (b.lots[0].size*b.lots[0].price + size*price) / total
+			b.lots[0].size = total
+		} else {
+			b.lots = append(b.lots, lot{size: size, price: price})
+		}
+	}
+	return realized
+}
+
+// Position returns the signed open size and its average cost.
+func (b *LotBook) Position() (size, avgPrice float64) {
+	notional := 0.0
+	for _, l := range b.lots {
+		size += l.size
+		notional += l.size * l.price
+	}
+	if math.Abs(size) > quantityEpsilon {
+		avgPrice = notional / size
+	}
+	return size, avgPrice
+}
+
+func sameSign(a, b float64) bool {
+	return (a > 0) == (b > 0)
+}
+
+type InstrumentPnl struct {
+	Instrument   string  `json:"instrument"`
+	Realized     float64 `json:"realized"`
+	Unrealized   float64 `json:"unrealized"`
+	Fees         float64 `json:"fees"`
+	Funding      float64 `json:"funding"`
+	Total        float64 `json:"total"`
+	NetSize      float64 `json:"netSize"`
+	AvgCost      float64 `json:"avgCost"`
+	MarkPrice    float64 `json:"markPrice"`
+	FillCount    int     `json:"fillCount"`
+	CostFromLots bool    `json:"costFromLots"`
+}
+
+type PnlReport struct {
+	PortfolioId string           `json:"portfolioId"`
+	Method      string           `json:"method"`
+	From        string           `json:"from,omitempty"`
+	To          string           `json:"to,omitempty"`
+	Instruments []*InstrumentPnl `json:"instruments"`
+	Total       *InstrumentPnl   `json:"total"`
+}
+
+// PnlInput is everything needed to compute a PnL report. Fills should cover
+// the whole history of the portfolio so that lots opened before From are
+// matched correctly; only PnL and fees of fills in [From, To) are reported.
+// With To set, Positions and MarkPrices are ignored: the lots open at To are
+// reported without unrealized PnL, since marks at To are not available.
+type PnlInput struct {
+	PortfolioId string
+	Method      string
+	From        time.Time
+	To          time.Time
+	Fills       []intx.Fill
+	Positions   []intx.Position
+	MarkPrices  map[string]float64
+	Funding     map[string]float64
+}
+
+func ComputePnl(input *PnlInput) (*PnlReport, error) {
+	fills := SortFillsByTime(input.Fills)
+
+	books := map[string]*LotBook{}
+	results := map[string]*InstrumentPnl{}
+	get := func(instrument string) *InstrumentPnl {
+		if r, ok := results[instrument]; ok {
+			return r
+		}
+		r := &InstrumentPnl{Instrument: instrument}
+		results[instrument] = r
+		return r
+	}
+
+	for _, f := range fills {
+		eventTime, err := time.Parse(time.RFC3339Nano, f.EventTime)
+		if err != nil {
+			return nil, fmt.Errorf("fill %s has invalid event time: %w", f.FillId, err)
+		}
+		if !input.To.IsZero() && !eventTime.Before(input.To) {
+			continue
+		}
+
+		book, ok := books[f.Symbol]
+		if !ok {
+			if book, err = NewLotBook(input.Method); err != nil {
+				return nil, err
+			}
+			books[f.Symbol] = book
+		}
+
+		size, err := ParseAmount(f.FillQty)
+		if err != nil {
+			return nil, fmt.Errorf("fill %s: %w", f.FillId, err)
+		}
+		price, err := ParseAmount(f.FillPrice)
+		if err != nil {
+			return nil, fmt.Errorf("fill %s: %w", f.FillId, err)
+		}
+		if !strings.EqualFold(f.Side, sideBuy) {
+			size = -size
+		}
+
+		realized := book.Apply(size, price)
+		if InTimeRange(eventTime, input.From, input.To) {
+			r := get(f.Symbol)
+			r.Realized += realized
+			r.Fees += ParseAmountOrZero(f.Fee)
+			r.FillCount++
+		}
+	}
+
+	if !input.To.IsZero() {
+		for symbol, book := range books {
+			if size, avgCost := book.Position(); math.Abs(size) > quantityEpsilon {
+				r := get(symbol)
+				r.NetSize = size
+				r.AvgCost = avgCost
+				r.CostFromLots = true
+			}
+		}
+	}
+
+	for _, p := range input.Positions {
+		if !input.To.IsZero() {
+			break
+		}
+		netSize := ParseAmountOrZero(p.NetSize)
+		if math.Abs(netSize) <= quantityEpsilon {
+			continue
+		}
+
+		r := get(p.Symbol)
+		r.NetSize = netSize
+		r.MarkPrice = input.MarkPrices[p.Symbol]
+		if r.MarkPrice == 0 {
+			r.MarkPrice = ParseAmountOrZero(p.MarkPrice)
+		}
+
+		r.AvgCost = ParseAmountOrZero(p.Vwap)
+		if book, ok := books[p.Symbol]; ok {
+			if lotSize, avgCost := book.Position(); math.Abs(lotSize-netSize) <= 1e-9 {
+				r.AvgCost = avgCost
+				r.CostFromLots = true
+			}
+		}
+		r.Unrealized = netSize * (r.MarkPrice - r.AvgCost)
+	}
+
+	for instrument, amount := range input.Funding {
+		get(instrument).Funding += amount
+	}
+
+	report := &PnlReport{
+		PortfolioId: input.PortfolioId,
+		Method:      input.Method,
+		Total:       &InstrumentPnl{Instrument: "TOTAL"},
+	}
+	if !input.From.IsZero() {
+		report.From = input.From.Format(time.RFC3339)
+	}
+	if !input.To.IsZero() {
+		report.To = input.To.Format(time.RFC3339)
+	}
+
+	for _, r := range results {
+		r.Total = r.Realized + r.Unrealized - r.Fees + r.Funding
+		report.Instruments = append(report.Instruments, r)
+
+		report.Total.Realized += r.Realized
+		report.Total.Unrealized += r.Unrealized
+		report.Total.Fees += r.Fees
+		report.Total.Funding += r.Funding
+		report.Total.Total += r.Total
+		report.Total.FillCount += r.FillCount
+	}
+	sort.Slice(report.Instruments, func(i, j int) bool {
+		return report.Instruments[i].Instrument < report.Instruments[j].Instrument
+	})
+
+	return report, nil
+}
+
+// SortFillsByTime returns the fills ordered by event time, oldest first.
+func SortFillsByTime(fills []intx.Fill) []intx.Fill {
+	sorted := append([]intx.Fill(nil), fills...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, erri := time.Parse(time.RFC3339Nano, sorted[i].EventTime)
+		tj, errj := time.Parse(time.RFC3339Nano, sorted[j].EventTime)
+		if erri != nil || errj != nil {
+			return sorted[i].EventTime < sorted[j].EventTime
+		}
+		return ti.Before(tj)
+	})
+	return sorted
+}
+
+// FundingByInstrument sums funding transfers of portfolioId in [from, to) per
+// instrument symbol. Payments out of the portfolio are negative.
+func FundingByInstrument(transfers []Transfer, portfolioId string, symbols map[string]string, from, to time.Time) map[string]float64 {
+	funding := map[string]float64{}
+	for _, t := range transfers {
+		if !strings.EqualFold(t.Type, TransferTypeFunding) {
+			continue
+		}
+		if createdAt, err := time.Parse(time.RFC3339Nano, t.CreatedAt); err == nil && !InTimeRange(createdAt, from, to) {
+			continue
+		}
+
+		amount := t.Amount
+		if t.FromPortfolio.Id == portfolioId || t.FromPortfolio.Uuid == portfolioId {
+			amount = -math.Abs(amount)
+		} else if t.ToPortfolio.Id == portfolioId || t.ToPortfolio.Uuid == portfolioId {
+			amount = math.Abs(amount)
+		}
+
+		instrumentId := strconv.FormatInt(t.InstrumentId, 10)
+		symbol, ok := symbols[instrumentId]
+		if !ok {
+			symbol = instrumentId
+		}
+		funding[symbol] += amount
+	}
+	return funding
+}
@@ -0,0 +1,158 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const DefaultConcurrency = 8
+
+// PortfolioState is a point-in-time view of one portfolio.
+type PortfolioState struct {
+	Portfolio  *intx.Portfolio `json:"portfolio"`
+	Summary    *intx.Summary   `json:"summary"`
+	Balances   []intx.Balance  `json:"balances"`
+	Positions  []intx.Position `json:"positions"`
+	OpenOrders []intx.Order    `json:"openOrders"`
+}
+
+func ListPortfolios(client *intx.Client) ([]*intx.Portfolio, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	response, err := client.ListPortfolios(ctx, &intx.ListPortfoliosRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list portfolios: %w", err)
+	}
+	return response.Portfolios, nil
+}
+
+// FindPortfolio matches id against the ID, UUID or name of portfolios.
+func FindPortfolio(portfolios []*intx.Portfolio, id string) (*intx.Portfolio, error) {
+	for _, p := range portfolios {
+		if p.PortfolioId == id || p.PortfolioUuid == id || p.Name == id {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("portfolio %s not found", id)
+}
+
+// SelectPortfolios resolves a comma separated list of portfolio IDs, UUIDs or
+// names, dropping duplicates.
+func SelectPortfolios(portfolios []*intx.Portfolio, ids string) ([]*intx.Portfolio, error) {
+	seen := map[string]bool{}
+	var selected []*intx.Portfolio
+	for _, id := range strings.Split(ids, ",") {
+		p, err := FindPortfolio(portfolios, strings.TrimSpace(id))
+		if err != nil {
+			return nil, err
+		}
+		if !seen[p.PortfolioId] {
+			seen[p.PortfolioId] = true
+			selected = append(selected, p)
+		}
+	}
+	return selected, nil
+}
+
+// FetchPortfolioState loads the summary, balances, positions and open orders
+// of a portfolio concurrently.
+func FetchPortfolioState(client *intx.Client, portfolio *intx.Portfolio) (*PortfolioState, error) {
+	state := &PortfolioState{Portfolio: portfolio}
+	portfolioId := portfolio.PortfolioId
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	run := func(i int, f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = f()
+		}()
+	}
+
+	run(0, func() error {
+		ctx, cancel := GetContextWithTimeout()
+		defer cancel()
+		response, err := client.GetPortfolioSummary(ctx, &intx.GetPortfolioSummaryRequest{PortfolioId: portfolioId})
+		if err != nil {
+			return fmt.Errorf("cannot get summary: %w", err)
+		}
+		state.Summary = response.Summary
+		return nil
+	})
+	run(1, func() error {
+		ctx, cancel := GetContextWithTimeout()
+		defer cancel()
+		response, err := client.GetPortfolioBalances(ctx, &intx.GetPortfolioBalancesRequest{PortfolioId: portfolioId})
+		if err != nil {
+			return fmt.Errorf("cannot get balances: %w", err)
+		}
+		state.Balances = response.Balances
+		return nil
+	})
+	run(2, func() (err error) {
+		state.Positions, err = GetPositions(client, portfolioId)
+		return err
+	})
+	run(3, func() (err error) {
+		state.OpenOrders, err = ListAllOpenOrders(client, portfolioId, "")
+		return err
+	})
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("portfolio %s: %w", portfolioId, err)
+		}
+	}
+	if state.Summary == nil {
+		state.Summary = &intx.Summary{}
+	}
+	return state, nil
+}
+
+// FetchPortfolioStates loads the state of each portfolio with at most
+// concurrency portfolios in flight. Results are in the order of portfolios;
+// failed portfolios have a nil state and a non-nil error.
+func FetchPortfolioStates(client *intx.Client, portfolios []*intx.Portfolio, concurrency int) ([]*PortfolioState, []error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	states := make([]*PortfolioState, len(portfolios))
+	errs := make([]error, len(portfolios))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, p := range portfolios {
+		wg.Add(1)
+		go func(i int, p *intx.Portfolio) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			states[i], errs[i] = FetchPortfolioState(client, p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	return states, errs
+}
@@ -0,0 +1,247 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+	"github.com/google/uuid"
+)
+
+const (
+	CloseMethodMarket = "market"
+	CloseMethodLimit  = "limit"
+
+	TifIoc = "IOC"
+	TifGtc = "GTC"
+)
+
+// closeOrderRequest adds close_only, which the SDK does not expose, so that
+// the exchange rejects a closing order that would increase or flip the
+// position.
+type closeOrderRequest struct {
+	*intx.CreateOrderRequest
+	CloseOnly bool `json:"close_only"`
+}
+
+// PositionSize returns the signed net size of a position, negative for shorts.
+func PositionSize(position intx.Position) float64 {
+	return ParseAmountOrZero(position.NetSize)
+}
+
+// PositionInstrument returns the symbol of a position, falling back to its
+// instrument ID.
+func PositionInstrument(position intx.Position) string {
+	return firstNonEmpty(position.Symbol, position.InstrumentId)
+}
+
+// OpenPositions drops positions with a zero net size.
+func OpenPositions(positions []intx.Position) []intx.Position {
+	var open []intx.Position
+	for _, p := range positions {
+		if PositionSize(p) != 0 {
+			open = append(open, p)
+		}
+	}
+	return open
+}
+
+// ClosingSide returns the order side that reduces position.
+func ClosingSide(position intx.Position) string {
+	if PositionSize(position) < 0 {
+		return SideBuy
+	}
+	return SideSell
+}
+
+// FindPosition returns the open position in instrument, matched against the
+// symbol or instrument ID of positions.
+func FindPosition(positions []intx.Position, instrument string) (intx.Position, error) {
+	for _, p := range OpenPositions(positions) {
+		if strings.EqualFold(p.Symbol, instrument) || p.InstrumentId == instrument || p.InstrumentUuid == instrument {
+			return p, nil
+		}
+	}
+	return intx.Position{}, fmt.Errorf("no open position in %s", instrument)
+}
+
+// ClosingOrder returns an IOC order that reduces position by size, which must
+// not exceed the position. Market orders need no quote; limit orders cross
+// the touch by slippage, a fraction, and are rounded away from the touch to
+// the quote increment of instrument, which may be nil.
+func ClosingOrder(portfolioId string, position intx.Position, size float64, method string, quote *intx.Quote, instrument *intx.Instrument, slippage float64) (*intx.CreateOrderRequest, error) {
+	net := PositionSize(position)
+	if net == 0 {
+		return nil, fmt.Errorf("no open position in %s", PositionInstrument(position))
+	}
+	if size <= 0 || size > math.Abs(net) {
+		return nil, fmt.Errorf("size %s must be positive and at most the position size %s", FormatAmount(size), FormatAmount(math.Abs(net)))
+	}
+
+	request := &intx.CreateOrderRequest{
+		ClientOrderId: uuid.New().String(),
+		PortfolioId:   portfolioId,
+		InstrumentId:  PositionInstrument(position),
+		Side:          ClosingSide(position),
+		Size:          FormatAmount(size),
+		Tif:           TifIoc,
+	}
+
+	switch strings.ToLower(method) {
+	case CloseMethodMarket:
+		request.Type = OrderTypeMarket
+	case CloseMethodLimit:
+		price, err := AggressivePrice(request.Side, quote, instrument, slippage)
+		if err != nil {
+			return nil, err
+		}
+		request.Type = OrderTypeLimit
+		request.Price = price
+	default:
+		return nil, fmt.Errorf("invalid method %s: must be %s or %s", method, CloseMethodMarket, CloseMethodLimit)
+	}
+	return request, nil
+}
+
+// TouchPrice returns the price an order of side would trade at first: the
+// best ask for buys and the best bid for sells, or the mark price when that
+// side of the book is empty.
+func TouchPrice(side string, quote *intx.Quote) (float64, error) {
+	if quote == nil {
+		return 0, errors.New("a quote is required")
+	}
+
+	price := ParseAmountOrZero(quote.BestBidPrice)
+	if strings.EqualFold(side, SideBuy) {
+		price = ParseAmountOrZero(quote.BestAskPrice)
+	}
+	if price <= 0 {
+		price = ParseAmountOrZero(quote.MarkPrice)
+	}
+	if price <= 0 {
+		return 0, errors.New("quote has no bid, ask or mark price")
+	}
+	return price, nil
+}
+
+// AggressivePrice returns a limit price that crosses the best price on the
+// other side of the book by slippage, using the mark price when that side
+// is empty.
+func AggressivePrice(side string, quote *intx.Quote, instrument *intx.Instrument, slippage float64) (string, error) {
+	reference, err := TouchPrice(side, quote)
+	if err != nil {
+		return "", err
+	}
+
+	buy := strings.EqualFold(side, SideBuy)
+	price := reference * (1 - slippage)
+	if buy {
+		price = reference * (1 + slippage)
+	}
+	increment := ""
+	if instrument != nil {
+		increment = instrument.QuoteIncrement
+	}
+	return RoundToIncrement(price, increment, buy), nil
+}
+
+// PassivePrice returns the best price on the side of the book of side, moved
+// ticks quote increments away from the spread. Negative ticks move towards
+// and through the spread. The mark price is used when that side is empty.
+func PassivePrice(side string, quote *intx.Quote, instrument *intx.Instrument, ticks int) (string, error) {
+	if quote == nil {
+		return "", errors.New("a quote is required for limit orders")
+	}
+
+	buy := strings.EqualFold(side, SideBuy)
+	reference := ParseAmountOrZero(quote.BestAskPrice)
+	if buy {
+		reference = ParseAmountOrZero(quote.BestBidPrice)
+	}
+	if reference <= 0 {
+		reference = ParseAmountOrZero(quote.MarkPrice)
+	}
+	if reference <= 0 {
+		return "", errors.New("quote has no bid, ask or mark price")
+	}
+
+	increment := ""
+	if instrument != nil {
+		increment = instrument.QuoteIncrement
+	}
+	tick := ParseAmountOrZero(increment)
+	if ticks != 0 && tick <= 0 {
+		return "", errors.New("instrument has no quote increment to offset by")
+	}
+
+	price := reference + float64(ticks)*tick
+	if buy {
+		price = reference - float64(ticks)*tick
+	}
+	if price <= 0 {
+		return "", fmt.Errorf("offset of %d ticks gives a price of %s", ticks, FormatAmount(price))
+	}
+	return RoundToIncrement(price, increment, !buy), nil
+}
+
+// RoundToIncrement rounds value down, or up, to a multiple of increment and
+// formats it with the decimals of increment. A blank increment leaves the
+// value as is.
+func RoundToIncrement(value float64, increment string, up bool) string {
+	step := ParseAmountOrZero(increment)
+	if step <= 0 {
+		return FormatAmount(value)
+	}
+
+	// Tolerate representation error so exact multiples stay put.
+	steps := value / step
+	if up {
+		steps = math.Ceil(steps - 1e-9)
+	} else {
+		steps = math.Floor(steps + 1e-9)
+	}
+
+	decimals := 0
+	if i := strings.IndexByte(increment, '.'); i >= 0 {
+		decimals = len(strings.TrimRight(increment[i+1:], "0"))
+	}
+	return FormatRounded(steps*step, decimals)
+}
+
+// CreateCloseOrder places a close-only order.
+func CreateCloseOrder(ctx context.Context, client *intx.Client, request *intx.CreateOrderRequest) (*intx.Order, error) {
+	order := &intx.Order{}
+	if err := CallApi(ctx, client, "POST", "/orders", nil, &closeOrderRequest{request, true}, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// IndexInstruments maps the instrument ID and symbol of each instrument to it.
+func IndexInstruments(instruments []*intx.Instrument) map[string]*intx.Instrument {
+	index := map[string]*intx.Instrument{}
+	for _, i := range instruments {
+		index[i.InstrumentId] = i
+		index[i.Symbol] = i
+	}
+	return index
+}
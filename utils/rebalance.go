@@ -0,0 +1,328 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultRebalanceDecimals = 2
+
+// RebalancePolicy describes the desired collateral of a set of portfolios.
+type RebalancePolicy struct {
+	Asset       string             `yaml:"asset" json:"asset"`
+	MinTransfer float64            `yaml:"minTransfer" json:"minTransfer"`
+	Decimals    *int               `yaml:"decimals" json:"decimals,omitempty"`
+	Portfolios  []*RebalanceTarget `yaml:"portfolios" json:"portfolios"`
+}
+
+// RebalanceTarget is the policy of one portfolio, identified by ID, UUID or
+// name. A balance target applies when the balance leaves [min, max], which
+// defaults to the target itself. A margin ratio band applies when maintenance
+// margin as a share of collateral leaves [min, max], and moves the ratio back
+// to its target. A reserve portfolio funds shortfalls and absorbs surpluses,
+// keeping at least min.
+type RebalanceTarget struct {
+	Portfolio   string      `yaml:"portfolio" json:"portfolio"`
+	Target      *float64    `yaml:"target" json:"target,omitempty"`
+	Min         *float64    `yaml:"min" json:"min,omitempty"`
+	Max         *float64    `yaml:"max" json:"max,omitempty"`
+	MarginRatio *MarginBand `yaml:"marginRatio" json:"marginRatio,omitempty"`
+	Reserve     bool        `yaml:"reserve" json:"reserve,omitempty"`
+}
+
+type MarginBand struct {
+	Min    float64  `yaml:"min" json:"min"`
+	Max    float64  `yaml:"max" json:"max"`
+	Target *float64 `yaml:"target" json:"target,omitempty"`
+}
+
+// RebalanceItem is the state of one portfolio and the change in collateral
+// its policy asks for: positive to receive, negative to release.
+type RebalanceItem struct {
+	PortfolioId string  `json:"portfolioId"`
+	Name        string  `json:"name"`
+	Balance     float64 `json:"balance"`
+	Available   float64 `json:"available"`
+	MarginRatio float64 `json:"marginRatio"`
+	Change      float64 `json:"change"`
+	Reason      string  `json:"reason"`
+}
+
+type RebalanceTransfer struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Asset  string  `json:"asset"`
+	Amount float64 `json:"amount"`
+}
+
+type RebalancePlan struct {
+	Asset     string               `json:"asset"`
+	Items     []*RebalanceItem     `json:"items"`
+	Transfers []*RebalanceTransfer `json:"transfers"`
+	Shortfall float64              `json:"shortfall,omitempty"`
+}
+
+func LoadRebalancePolicy(path string) (*RebalancePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy: %w", err)
+	}
+
+	policy := &RebalancePolicy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("cannot parse policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (p *RebalancePolicy) Validate() error {
+	if p.Asset == "" {
+		return errors.New("asset is required")
+	}
+	if len(p.Portfolios) == 0 {
+		return errors.New("at least one portfolio is required")
+	}
+
+	reserves := 0
+	seen := map[string]bool{}
+	for _, t := range p.Portfolios {
+		if t.Portfolio == "" {
+			return errors.New("portfolio is required for every entry")
+		}
+		if seen[t.Portfolio] {
+			return fmt.Errorf("portfolio %s is listed more than once", t.Portfolio)
+		}
+		seen[t.Portfolio] = true
+
+		if t.Reserve {
+			reserves++
+			continue
+		}
+		if (t.Target == nil) == (t.MarginRatio == nil) {
+			return fmt.Errorf("portfolio %s: exactly one of target or marginRatio is required", t.Portfolio)
+		}
+		if t.Target != nil && ((t.Min != nil && *t.Min > *t.Target) || (t.Max != nil && *t.Max < *t.Target)) {
+			return fmt.Errorf("portfolio %s: target must be within min and max", t.Portfolio)
+		}
+		if b := t.MarginRatio; b != nil {
+			if b.Min < 0 || b.Max <= b.Min || (b.Target != nil && (*b.Target < b.Min || *b.Target > b.Max)) {
+				return fmt.Errorf("portfolio %s: margin ratio band must satisfy 0 <= min <= target <= max", t.Portfolio)
+			}
+		}
+	}
+	if reserves > 1 {
+		return errors.New("at most one reserve portfolio is allowed")
+	}
+	return nil
+}
+
+func (p *RebalancePolicy) decimals() int {
+	if p.Decimals == nil {
+		return defaultRebalanceDecimals
+	}
+	return *p.Decimals
+}
+
+// AssetBalance returns the balance of asset and the amount that can be
+// withdrawn from the portfolio.
+func AssetBalance(balances []intx.Balance, asset string) (quantity, available float64) {
+	for _, b := range balances {
+		if !strings.EqualFold(b.AssetName, asset) && !strings.EqualFold(b.AssetId, asset) {
+			continue
+		}
+		quantity = ParseAmountOrZero(b.Quantity)
+		if b.MaxWithdrawAmount != "" {
+			available = ParseAmountOrZero(b.MaxWithdrawAmount)
+		} else {
+			available = quantity - ParseAmountOrZero(b.Hold)
+		}
+		return quantity, math.Max(available, 0)
+	}
+	return 0, 0
+}
+
+// PlanRebalance computes the change each portfolio needs and matches the
+// largest surpluses with the largest deficits, which needs at most one
+// transfer fewer than the number of portfolios involved. states must be in
+// the order of policy.Portfolios.
+func PlanRebalance(policy *RebalancePolicy, states []*PortfolioState) *RebalancePlan {
+	plan := &RebalancePlan{Asset: policy.Asset}
+
+	var reserve *RebalanceItem
+	var reserveTarget *RebalanceTarget
+	for i, t := range policy.Portfolios {
+		state := states[i]
+		item := &RebalanceItem{
+			PortfolioId: state.Portfolio.PortfolioId,
+			Name:        state.Portfolio.Name,
+			MarginRatio: ReportedMarginState(state.Summary).MarginRatio,
+		}
+		item.Balance, item.Available = AssetBalance(state.Balances, policy.Asset)
+		plan.Items = append(plan.Items, item)
+
+		switch {
+		case t.Reserve:
+			reserve, reserveTarget = item, t
+			item.Reason = "reserve"
+		case t.Target != nil:
+			planBalanceTarget(item, t)
+		default:
+			planMarginBand(item, t.MarginRatio, ReportedMarginState(state.Summary))
+		}
+
+		if item.Change < 0 {
+			item.Change = -math.Min(-item.Change, item.Available)
+		}
+	}
+
+	var surplus, deficit float64
+	for _, item := range plan.Items {
+		if item.Change > 0 {
+			deficit += item.Change
+		} else {
+			surplus -= item.Change
+		}
+	}
+
+	if reserve != nil {
+		keep := 0.0
+		if reserveTarget.Min != nil {
+			keep = *reserveTarget.Min
+		}
+		if deficit > surplus {
+			reserve.Change = -math.Min(deficit-surplus, math.Max(math.Min(reserve.Available, reserve.Balance-keep), 0))
+			surplus -= reserve.Change
+		} else if surplus > deficit {
+			reserve.Change = surplus - deficit
+			deficit = surplus
+		}
+	}
+	if deficit > surplus {
+		plan.Shortfall = deficit - surplus
+	}
+
+	plan.Transfers = matchTransfers(plan.Items, policy)
+	return plan
+}
+
+func planBalanceTarget(item *RebalanceItem, t *RebalanceTarget) {
+	low, high := *t.Target, *t.Target
+	if t.Min != nil {
+		low = *t.Min
+	}
+	if t.Max != nil {
+		high = *t.Max
+	}
+
+	switch {
+	case item.Balance < low:
+		item.Reason = fmt.Sprintf("balance below %s", FormatAmount(low))
+	case item.Balance > high:
+		item.Reason = fmt.Sprintf("balance above %s", FormatAmount(high))
+	default:
+		item.Reason = "within band"
+		return
+	}
+	item.Change = *t.Target - item.Balance
+}
+
+func planMarginBand(item *RebalanceItem, band *MarginBand, state *MarginState) {
+	target := (band.Min + band.Max) / 2
+	if band.Target != nil {
+		target = *band.Target
+	}
+
+	switch {
+	case item.MarginRatio > band.Max:
+		item.Reason = fmt.Sprintf("margin ratio above %s%%", FormatRounded(band.Max*100, 2))
+	case item.MarginRatio < band.Min:
+		item.Reason = fmt.Sprintf("margin ratio below %s%%", FormatRounded(band.Min*100, 2))
+	default:
+		item.Reason = "within band"
+		return
+	}
+
+	required := 0.0
+	if target > 0 {
+		required = state.MaintenanceMargin / target
+	}
+	item.Change = required - state.Collateral
+}
+
+// matchTransfers pairs the largest remaining surplus with the largest
+// remaining deficit until either side is exhausted. Amounts are rounded down
+// to the policy decimals and transfers below the minimum are dropped.
+func matchTransfers(items []*RebalanceItem, policy *RebalancePolicy) []*RebalanceTransfer {
+	type side struct {
+		item   *RebalanceItem
+		amount float64
+	}
+	var sources, sinks []*side
+	for _, item := range items {
+		if item.Change < 0 {
+			sources = append(sources, &side{item, -item.Change})
+		} else if item.Change > 0 {
+			sinks = append(sinks, &side{item, item.Change})
+		}
+	}
+
+	scale := math.Pow10(policy.decimals())
+	minTransfer := math.Max(policy.MinTransfer, 1/scale)
+
+	var transfers []*RebalanceTransfer
+	for {
+		sort.SliceStable(sources, func(i, j int) bool { return sources[i].amount > sources[j].amount })
+		sort.SliceStable(sinks, func(i, j int) bool { return sinks[i].amount > sinks[j].amount })
+		if len(sources) == 0 || len(sinks) == 0 {
+			return transfers
+		}
+
+		from, to := sources[0], sinks[0]
+		amount := math.Floor(math.Min(from.amount, to.amount)*scale) / scale
+		if amount < minTransfer {
+			return transfers
+		}
+
+		transfers = append(transfers, &RebalanceTransfer{
+			From:   from.item.PortfolioId,
+			To:     to.item.PortfolioId,
+			Asset:  policy.Asset,
+			Amount: amount,
+		})
+
+		from.amount -= amount
+		to.amount -= amount
+		if from.amount < minTransfer {
+			sources = sources[1:]
+		}
+		if to.amount < minTransfer {
+			sinks = sinks[1:]
+		}
+	}
+}
@@ -0,0 +1,307 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	ReconcileMatched           = "matched"
+	ReconcileMismatched        = "mismatched"
+	ReconcileMissingOnExchange = "missing_on_exchange"
+	ReconcileMissingInLedger   = "missing_in_ledger"
+	ReconcileDuplicateInLedger = "duplicate_in_ledger"
+)
+
+// ledgerColumns maps accepted ledger header names to the field they hold.
+var ledgerColumns = map[string]string{
+	"fill_id":         "fill_id",
+	"exec_id":         "fill_id",
+	"client_order_id": "client_order_id",
+	"clordid":         "client_order_id",
+	"instrument":      "instrument",
+	"symbol":          "instrument",
+	"side":            "side",
+	"quantity":        "quantity",
+	"qty":             "quantity",
+	"size":            "quantity",
+	"fill_qty":        "quantity",
+	"price":           "price",
+	"fill_price":      "price",
+	"time":            "time",
+	"timestamp":       "time",
+	"event_time":      "time",
+}
+
+// LedgerFill is one booking from the internal ledger. Instrument, side and
+// time are optional; either the fill ID or the client order ID is required.
+type LedgerFill struct {
+	Line          int       `json:"line"`
+	FillId        string    `json:"fillId,omitempty"`
+	ClientOrderId string    `json:"clientOrderId,omitempty"`
+	Instrument    string    `json:"instrument,omitempty"`
+	Side          string    `json:"side,omitempty"`
+	Quantity      float64   `json:"quantity"`
+	Price         float64   `json:"price"`
+	Time          time.Time `json:"time,omitempty"`
+}
+
+// ReadLedger reads a CSV ledger with a header row. Header names are matched
+// case insensitively, see ledgerColumns.
+func ReadLedger(path string) ([]*LedgerFill, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open ledger: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ledger header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(name)))
+		if field, ok := ledgerColumns[name]; ok {
+			if _, dup := index[field]; !dup {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"quantity", "price"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("ledger %s has no %s column", path, field)
+		}
+	}
+	_, hasFillId := index["fill_id"]
+	_, hasClientOrderId := index["client_order_id"]
+	if !hasFillId && !hasClientOrderId {
+		return nil, fmt.Errorf("ledger %s needs a fill_id or client_order_id column", path)
+	}
+
+	var fills []*LedgerFill
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return fills, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ledger line %d: %w", line, err)
+		}
+
+		value := func(field string) string {
+			if i, ok := index[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		fill := &LedgerFill{
+			Line:          line,
+			FillId:        value("fill_id"),
+			ClientOrderId: value("client_order_id"),
+			Instrument:    value("instrument"),
+			Side:          strings.ToUpper(value("side")),
+		}
+		if fill.FillId == "" && fill.ClientOrderId == "" {
+			return nil, fmt.Errorf("ledger line %d: fill_id or client_order_id is required", line)
+		}
+		if fill.Quantity, err = ParseAmount(value("quantity")); err != nil {
+			return nil, fmt.Errorf("ledger line %d: invalid quantity: %w", line, err)
+		}
+		if fill.Price, err = ParseAmount(value("price")); err != nil {
+			return nil, fmt.Errorf("ledger line %d: invalid price: %w", line, err)
+		}
+		if t := value("time"); t != "" {
+			if fill.Time, err = ParseTime(t); err != nil {
+				return nil, fmt.Errorf("ledger line %d: %w", line, err)
+			}
+		}
+		fills = append(fills, fill)
+	}
+}
+
+type ReconcileTolerances struct {
+	Price    Tolerance
+	Quantity Tolerance
+}
+
+// ReconcileItem pairs a ledger booking with an exchange fill. Either side is
+// nil when it is missing.
+type ReconcileItem struct {
+	Status      string      `json:"status"`
+	Ledger      *LedgerFill `json:"ledger,omitempty"`
+	Exchange    *intx.Fill  `json:"exchange,omitempty"`
+	Differences []string    `json:"differences,omitempty"`
+}
+
+type ReconcileResult struct {
+	Counts map[string]int   `json:"counts"`
+	Items  []*ReconcileItem `json:"items"`
+}
+
+func (r *ReconcileResult) Breaks() int {
+	return r.Counts[ReconcileMismatched] + r.Counts[ReconcileMissingOnExchange] + r.Counts[ReconcileMissingInLedger] +
+		r.Counts[ReconcileDuplicateInLedger]
+}
+
+func (r *ReconcileResult) add(item *ReconcileItem) {
+	r.Counts[item.Status]++
+	r.Items = append(r.Items, item)
+}
+
+// Reconcile matches ledger bookings to exchange fills. Bookings with a fill
+// ID are matched on it; the others are matched to an unmatched fill of the
+// same client order ID, preferring one whose price and size are within
+// tolerance. Matched pairs that differ are reported as mismatched, and
+// bookings repeating the fill ID of an earlier booking as duplicates. Exchange
+// fills repeating a fill ID, as offset pagination can return, are used once.
+func Reconcile(ledger []*LedgerFill, fills []intx.Fill, tolerances ReconcileTolerances) *ReconcileResult {
+	fills = uniqueFills(fills)
+	result := &ReconcileResult{Counts: map[string]int{
+		ReconcileMatched:           0,
+		ReconcileMismatched:        0,
+		ReconcileMissingOnExchange: 0,
+		ReconcileMissingInLedger:   0,
+		ReconcileDuplicateInLedger: 0,
+	}}
+
+	byFillId := map[string]int{}
+	byClientOrderId := map[string][]int{}
+	for i, f := range fills {
+		byFillId[f.FillId] = i
+		if f.ClientOrderId != "" {
+			byClientOrderId[f.ClientOrderId] = append(byClientOrderId[f.ClientOrderId], i)
+		}
+	}
+	matched := make([]bool, len(fills))
+
+	pair := func(l *LedgerFill, i int) {
+		matched[i] = true
+		fill := fills[i]
+		item := &ReconcileItem{Status: ReconcileMatched, Ledger: l, Exchange: &fill}
+		if item.Differences = compareLedgerFill(l, fill, tolerances); len(item.Differences) > 0 {
+			item.Status = ReconcileMismatched
+		}
+		result.add(item)
+	}
+
+	var byClientOrder []*LedgerFill
+	ledgerLines := map[string]int{}
+	for _, l := range ledger {
+		if l.FillId == "" {
+			byClientOrder = append(byClientOrder, l)
+			continue
+		}
+		if line, ok := ledgerLines[l.FillId]; ok {
+			item := &ReconcileItem{
+				Status:      ReconcileDuplicateInLedger,
+				Ledger:      l,
+				Differences: []string{fmt.Sprintf("fill_id also booked on line %d", line)},
+			}
+			if i, ok := byFillId[l.FillId]; ok {
+				fill := fills[i]
+				item.Exchange = &fill
+			}
+			result.add(item)
+			continue
+		}
+		ledgerLines[l.FillId] = l.Line
+
+		if i, ok := byFillId[l.FillId]; ok && !matched[i] {
+			pair(l, i)
+		} else {
+			result.add(&ReconcileItem{Status: ReconcileMissingOnExchange, Ledger: l})
+		}
+	}
+
+	// Bookings by client order ID go second so that they cannot take fills
+	// claimed by a fill ID.
+	for _, l := range byClientOrder {
+		best := -1
+		for _, i := range byClientOrderId[l.ClientOrderId] {
+			if matched[i] {
+				continue
+			}
+			if best < 0 {
+				best = i
+			}
+			if len(compareLedgerFill(l, fills[i], tolerances)) == 0 {
+				best = i
+				break
+			}
+		}
+		if best < 0 {
+			result.add(&ReconcileItem{Status: ReconcileMissingOnExchange, Ledger: l})
+			continue
+		}
+		pair(l, best)
+	}
+
+	for i := range fills {
+		if !matched[i] {
+			fill := fills[i]
+			result.add(&ReconcileItem{Status: ReconcileMissingInLedger, Exchange: &fill})
+		}
+	}
+	return result
+}
+
+func compareLedgerFill(l *LedgerFill, f intx.Fill, tolerances ReconcileTolerances) []string {
+	var differences []string
+	if l.ClientOrderId != "" && f.ClientOrderId != "" && l.ClientOrderId != f.ClientOrderId {
+		differences = append(differences, "client_order_id")
+	}
+	if l.Instrument != "" && !strings.EqualFold(l.Instrument, f.Symbol) && l.Instrument != f.InstrumentId {
+		differences = append(differences, "instrument")
+	}
+	if l.Side != "" && !strings.EqualFold(l.Side, f.Side) {
+		differences = append(differences, "side")
+	}
+	if tolerances.Quantity.Differs(l.Quantity, ParseAmountOrZero(f.FillQty)) {
+		differences = append(differences, "quantity")
+	}
+	if tolerances.Price.Differs(l.Price, ParseAmountOrZero(f.FillPrice)) {
+		differences = append(differences, "price")
+	}
+	return differences
+}
+
+func uniqueFills(fills []intx.Fill) []intx.Fill {
+	seen := map[string]bool{}
+	unique := make([]intx.Fill, 0, len(fills))
+	for _, f := range fills {
+		if !seen[f.FillId] {
+			seen[f.FillId] = true
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
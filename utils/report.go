@@ -0,0 +1,100 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	OutputFormatTable = "table"
+	OutputFormatCsv   = "csv"
+	OutputFormatJson  = "json"
+)
+
+// Report is a tabular view of a command result. Value is what is printed for
+// JSON output; Headers and Rows are used for table and CSV output.
+type Report struct {
+	Value   interface{}
+	Headers []string
+	Rows    [][]string
+}
+
+func (r *Report) AddRow(values ...string) {
+	r.Rows = append(r.Rows, values)
+}
+
+// PrintReport writes the report in the format selected by the output-format
+// flag.
+func PrintReport(cmd *cobra.Command, report *Report) error {
+	return PrintReports(cmd, report.Value, report)
+}
+
+// PrintReports writes several tables separated by blank lines, or value once
+// when JSON output is selected.
+func PrintReports(cmd *cobra.Command, value interface{}, reports ...*Report) error {
+	format := strings.ToLower(GetFlagStringValue(cmd, OutputFormatFlag))
+	if format == OutputFormatJson {
+		return PrintJsonResponse(cmd, value)
+	}
+
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		var err error
+		switch format {
+		case "", OutputFormatTable:
+			err = WriteTable(os.Stdout, report.Headers, report.Rows)
+		case OutputFormatCsv:
+			err = WriteCsv(os.Stdout, report.Headers, report.Rows)
+		default:
+			return fmt.Errorf("unsupported output format %s: must be table, csv or json", format)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func WriteTable(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func WriteCsv(w io.Writer, headers []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,299 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	schedulerStateFileName = "scheduler_state.json"
+	schedulerLogDirName    = "scheduler"
+
+	scheduledCommandName = "intxctl"
+
+	defaultJobTimeout = 5 * time.Minute
+	defaultMaxLogSize = 10 << 20
+	defaultLogBackups = 5
+
+	JobStatusOk       = "ok"
+	JobStatusFailed   = "failed"
+	JobStatusTimeout  = "timeout"
+	JobStatusRunning  = "running"
+	JobStatusNeverRun = "never run"
+)
+
+// SchedulerConfig is the jobs file of the scheduler. Output of each job is
+// appended to a log file in LogDir, rotated when it exceeds MaxLogSize bytes.
+type SchedulerConfig struct {
+	LogDir     string          `yaml:"logDir"`
+	MaxLogSize int64           `yaml:"maxLogSize"`
+	LogBackups int             `yaml:"logBackups"`
+	State      string          `yaml:"state"`
+	Jobs       []*ScheduledJob `yaml:"jobs"`
+}
+
+// ScheduledJob runs an intxctl command line on a cron expression, or every
+// interval starting one interval after the scheduler starts.
+type ScheduledJob struct {
+	Name     string        `yaml:"name"`
+	Command  string        `yaml:"command"`
+	Cron     string        `yaml:"cron"`
+	Every    time.Duration `yaml:"every"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Output   string        `yaml:"output"`
+	Args     []string      `yaml:"-"`
+	schedule cron.Schedule
+}
+
+// JobState is the last known state of a job, as shown by scheduler status.
+type JobState struct {
+	Schedule     string    `json:"schedule"`
+	Status       string    `json:"status"`
+	LastStart    time.Time `json:"lastStart,omitempty"`
+	LastDuration string    `json:"lastDuration,omitempty"`
+	LastError    string    `json:"lastError,omitempty"`
+	NextRun      time.Time `json:"nextRun"`
+	Runs         int       `json:"runs"`
+	Failures     int       `json:"failures"`
+	Skipped      int       `json:"skipped"`
+	Output       string    `json:"output"`
+}
+
+type SchedulerState struct {
+	Pid       int                  `json:"pid"`
+	StartedAt time.Time            `json:"startedAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+	Jobs      map[string]*JobState `json:"jobs"`
+}
+
+func LoadSchedulerConfig(path string) (*SchedulerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read jobs: %w", err)
+	}
+
+	config := &SchedulerConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("cannot parse jobs: %w", err)
+	}
+	if err := config.init(); err != nil {
+		return nil, fmt.Errorf("invalid jobs: %w", err)
+	}
+	return config, nil
+}
+
+func (c *SchedulerConfig) init() error {
+	if c.MaxLogSize == 0 {
+		c.MaxLogSize = defaultMaxLogSize
+	}
+	if c.LogBackups == 0 {
+		c.LogBackups = defaultLogBackups
+	}
+	if c.MaxLogSize < 0 || c.LogBackups < 0 {
+		return errors.New("maxLogSize and logBackups cannot be negative")
+	}
+	if c.LogDir == "" {
+		dir, err := GetCliHomePath(schedulerLogDirName)
+		if err != nil {
+			return err
+		}
+		c.LogDir = dir
+	}
+	if c.State == "" {
+		path, err := GetSchedulerStatePath()
+		if err != nil {
+			return err
+		}
+		c.State = path
+	}
+
+	if len(c.Jobs) == 0 {
+		return errors.New("at least one job is required")
+	}
+	names := map[string]bool{}
+	for _, job := range c.Jobs {
+		if job.Name == "" {
+			return errors.New("name is required for every job")
+		}
+		if names[job.Name] {
+			return fmt.Errorf("job %s is listed more than once", job.Name)
+		}
+		names[job.Name] = true
+		if err := job.init(c.LogDir); err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+	}
+	return nil
+}
+
+func (job *ScheduledJob) init(logDir string) error {
+	args, err := SplitCommandLine(job.Command)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == scheduledCommandName {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return errors.New("command is required")
+	}
+	job.Args = args
+
+	switch {
+	case job.Cron != "" && job.Every != 0:
+		return errors.New("only one of cron or every is allowed")
+	case job.Cron != "":
+		if job.schedule, err = cron.ParseStandard(job.Cron); err != nil {
+			return fmt.Errorf("invalid cron expression: %w", err)
+		}
+	case job.Every > 0:
+		job.schedule = cron.Every(job.Every)
+	default:
+		return errors.New("a cron expression or a positive every interval is required")
+	}
+
+	if job.Timeout == 0 {
+		job.Timeout = defaultJobTimeout
+	}
+	if job.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if job.Output == "" {
+		job.Output = filepath.Join(logDir, job.Name+".log")
+	}
+	return nil
+}
+
+// Next returns the first run time of the job after t.
+func (job *ScheduledJob) Next(t time.Time) time.Time {
+	return job.schedule.Next(t)
+}
+
+func (job *ScheduledJob) ScheduleText() string {
+	if job.Cron != "" {
+		return job.Cron
+	}
+	return "every " + job.Every.String()
+}
+
+// SplitCommandLine splits a command line into arguments, honoring single and
+// double quotes and backslash escapes outside single quotes.
+func SplitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// OpenJobOutput opens the output file of a job for appending, first rotating
+// it to path.1, path.2 and so on when it has reached maxSize bytes.
+func OpenJobOutput(path string, maxSize int64, backups int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("cannot create %s: %w", filepath.Dir(path), err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxSize {
+		if err := rotateFile(path, backups); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func rotateFile(path string, backups int) error {
+	if backups == 0 {
+		return os.Truncate(path, 0)
+	}
+	for i := backups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil {
+				return fmt.Errorf("cannot rotate %s: %w", from, err)
+			}
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("cannot rotate %s: %w", path, err)
+	}
+	return nil
+}
+
+func GetSchedulerStatePath() (string, error) {
+	return GetCliHomePath(schedulerStateFileName)
+}
+
+func LoadSchedulerState(path string) (*SchedulerState, error) {
+	state := &SchedulerState{Jobs: map[string]*JobState{}}
+	if err := ReadJsonFile(path, state); err != nil {
+		return nil, err
+	}
+	if state.Jobs == nil {
+		state.Jobs = map[string]*JobState{}
+	}
+	return state, nil
+}
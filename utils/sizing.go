@@ -0,0 +1,83 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// OrderSizing derives an order size in base units from one of a notional
+// amount, a fraction of available collateral or a fixed loss at a stop price.
+// Unset methods are zero.
+type OrderSizing struct {
+	Notional   float64 `json:"notional,omitempty"`
+	PctBalance float64 `json:"pctBalance,omitempty"`
+	Risk       float64 `json:"risk,omitempty"`
+	StopPrice  float64 `json:"stopPrice,omitempty"`
+}
+
+// AvailableCollateral sums the collateral value of balances, less the share of
+// each balance held for orders and transfers.
+func AvailableCollateral(balances []intx.Balance) float64 {
+	total := 0.0
+	for _, b := range balances {
+		value := ParseAmountOrZero(b.CollateralValue)
+		quantity := ParseAmountOrZero(b.Quantity)
+		if value <= 0 || quantity <= 0 {
+			continue
+		}
+		free := quantity - ParseAmountOrZero(b.Hold) - ParseAmountOrZero(b.TransferHold)
+		total += value * math.Max(free, 0) / quantity
+	}
+	return total
+}
+
+// Size returns the unrounded size of an order of side entering at price.
+// collateral is only used for PctBalance.
+func (s *OrderSizing) Size(side string, price, collateral float64) (float64, error) {
+	if price <= 0 {
+		return 0, errors.New("entry price must be positive")
+	}
+
+	switch {
+	case s.Notional > 0:
+		return s.Notional / price, nil
+	case s.PctBalance > 0:
+		if collateral <= 0 {
+			return 0, errors.New("no available collateral")
+		}
+		return collateral * s.PctBalance / price, nil
+	case s.Risk > 0:
+		if s.StopPrice <= 0 {
+			return 0, errors.New("risk sizing needs a stop price")
+		}
+		distance := price - s.StopPrice
+		if strings.EqualFold(side, SideSell) {
+			distance = -distance
+		}
+		if distance <= 0 {
+			return 0, fmt.Errorf("stop price %s must be on the losing side of the entry price %s", FormatAmount(s.StopPrice), FormatAmount(price))
+		}
+		return s.Risk / distance, nil
+	}
+	return 0, errors.New("no sizing method given")
+}
@@ -0,0 +1,276 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	SnapshotVersion = 1
+
+	SnapshotFormatJson   = "json"
+	SnapshotFormatSqlite = "sqlite"
+
+	sqliteHeader = "SQLite format 3\x00"
+)
+
+// Snapshot is a point-in-time record of one or more portfolios. Version is
+// incremented whenever the layout changes incompatibly.
+type Snapshot struct {
+	Version     int               `json:"version"`
+	CreatedAt   string            `json:"createdAt"`
+	Profile     string            `json:"profile,omitempty"`
+	Environment string            `json:"environment"`
+	BaseUrl     string            `json:"baseUrl"`
+	Portfolios  []*PortfolioState `json:"portfolios"`
+}
+
+// SnapshotFormatForPath picks the format from the file extension.
+func SnapshotFormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return SnapshotFormatSqlite
+	default:
+		return SnapshotFormatJson
+	}
+}
+
+// WriteSnapshot writes a new snapshot file. Existing files are never
+// overwritten so that earlier records stay intact.
+func WriteSnapshot(path, format string, snapshot *Snapshot) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	switch format {
+	case SnapshotFormatJson:
+		return WriteJsonFile(path, snapshot)
+	case SnapshotFormatSqlite:
+		return writeSqliteSnapshot(path, snapshot)
+	default:
+		return fmt.Errorf("unsupported snapshot format %s: must be json or sqlite", format)
+	}
+}
+
+// ReadSnapshot reads a JSON or SQLite snapshot, detected from its contents.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot: %w", err)
+	}
+
+	var snapshot *Snapshot
+	if bytes.HasPrefix(data, []byte(sqliteHeader)) {
+		if snapshot, err = readSqliteSnapshot(path); err != nil {
+			return nil, err
+		}
+	} else {
+		snapshot = &Snapshot{}
+		if err := json.Unmarshal(data, snapshot); err != nil {
+			return nil, fmt.Errorf("cannot parse snapshot %s: %w", path, err)
+		}
+	}
+
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot %s has unsupported version %d", path, snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+// Tolerance decides whether two values differ. A relative tolerance is a
+// fraction of the larger magnitude.
+type Tolerance struct {
+	Absolute float64
+	Relative float64
+}
+
+// ParseTolerance parses absolute values such as 0.01 and relative values
+// such as 0.5%.
+func ParseTolerance(value string) (Tolerance, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		f, err := ParsePercent(value)
+		if err != nil || f < 0 {
+			return Tolerance{}, fmt.Errorf("invalid tolerance %s", value)
+		}
+		return Tolerance{Relative: f}, nil
+	}
+
+	f, err := ParseAmount(value)
+	if err != nil || f < 0 {
+		return Tolerance{}, fmt.Errorf("invalid tolerance %s", value)
+	}
+	return Tolerance{Absolute: f}, nil
+}
+
+func (t Tolerance) Differs(a, b float64) bool {
+	diff := math.Abs(a - b)
+	if t.Relative > 0 {
+		return diff > t.Relative*math.Max(math.Abs(a), math.Abs(b))
+	}
+	return diff > t.Absolute
+}
+
+const (
+	SnapshotChangeAdded   = "added"
+	SnapshotChangeRemoved = "removed"
+	SnapshotChangeChanged = "changed"
+)
+
+type SnapshotChange struct {
+	PortfolioId string `json:"portfolioId"`
+	Category    string `json:"category"`
+	Key         string `json:"key"`
+	Field       string `json:"field,omitempty"`
+	Change      string `json:"change"`
+	Before      string `json:"before,omitempty"`
+	After       string `json:"after,omitempty"`
+}
+
+// DiffSnapshots reports balance, position and open order changes from a to b.
+// Numeric fields within tolerance are considered unchanged.
+func DiffSnapshots(a, b *Snapshot, tolerance Tolerance) []*SnapshotChange {
+	before := portfoliosById(a)
+	after := portfoliosById(b)
+
+	var ids []string
+	for id := range before {
+		ids = append(ids, id)
+	}
+	for id := range after {
+		if _, ok := before[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var changes []*SnapshotChange
+	for _, id := range ids {
+		x, y := before[id], after[id]
+		if x == nil || y == nil {
+			change := SnapshotChangeAdded
+			if y == nil {
+				change = SnapshotChangeRemoved
+			}
+			changes = append(changes, &SnapshotChange{PortfolioId: id, Category: "portfolio", Key: id, Change: change})
+			continue
+		}
+
+		d := &snapshotDiff{portfolioId: id, tolerance: tolerance}
+		d.compare("balance", balancesByAsset(x.Balances), balancesByAsset(y.Balances), []string{"quantity", "hold"})
+		d.compare("position", positionsBySymbol(x.Positions), positionsBySymbol(y.Positions), []string{"netSize"})
+		d.compare("order", ordersById(x.OpenOrders), ordersById(y.OpenOrders), []string{"size", "price", "stopPrice", "leavesQty"})
+		changes = append(changes, d.changes...)
+	}
+	return changes
+}
+
+func portfoliosById(s *Snapshot) map[string]*PortfolioState {
+	states := map[string]*PortfolioState{}
+	for _, state := range s.Portfolios {
+		states[state.Portfolio.PortfolioId] = state
+	}
+	return states
+}
+
+type snapshotDiff struct {
+	portfolioId string
+	tolerance   Tolerance
+	changes     []*SnapshotChange
+}
+
+// compare diffs entities keyed by name, each a map of numeric fields.
+func (d *snapshotDiff) compare(category string, before, after map[string]map[string]string, fields []string) {
+	var keys []string
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		x, inBefore := before[key]
+		y, inAfter := after[key]
+		switch {
+		case !inBefore:
+			d.add(category, key, "", SnapshotChangeAdded, "", y[fields[0]])
+		case !inAfter:
+			d.add(category, key, "", SnapshotChangeRemoved, x[fields[0]], "")
+		default:
+			for _, field := range fields {
+				if d.tolerance.Differs(ParseAmountOrZero(x[field]), ParseAmountOrZero(y[field])) {
+					d.add(category, key, field, SnapshotChangeChanged, x[field], y[field])
+				}
+			}
+		}
+	}
+}
+
+func (d *snapshotDiff) add(category, key, field, change, before, after string) {
+	d.changes = append(d.changes, &SnapshotChange{
+		PortfolioId: d.portfolioId,
+		Category:    category,
+		Key:         key,
+		Field:       field,
+		Change:      change,
+		Before:      before,
+		After:       after,
+	})
+}
+
+func balancesByAsset(balances []intx.Balance) map[string]map[string]string {
+	result := map[string]map[string]string{}
+	for _, b := range balances {
+		result[firstNonEmpty(b.AssetName, b.AssetId)] = map[string]string{"quantity": b.Quantity, "hold": b.Hold}
+	}
+	return result
+}
+
+func positionsBySymbol(positions []intx.Position) map[string]map[string]string {
+	result := map[string]map[string]string{}
+	for _, p := range positions {
+		result[firstNonEmpty(p.Symbol, p.InstrumentId)] = map[string]string{"netSize": p.NetSize}
+	}
+	return result
+}
+
+func ordersById(orders []intx.Order) map[string]map[string]string {
+	result := map[string]map[string]string{}
+	for _, o := range orders {
+		result[o.OrderId] = map[string]string{
+			"size":      o.Size,
+			"price":     o.Price,
+			"stopPrice": o.StopPrice,
+			"leavesQty": o.LeavesQty,
+		}
+	}
+	return result
+}
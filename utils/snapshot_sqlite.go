@@ -0,0 +1,196 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+// The key columns are for ad-hoc SQL; the data column holds the full record
+// and is what snapshots are read back from.
+var snapshotSchema = []string{
+	`CREATE TABLE snapshot (version INTEGER NOT NULL, created_at TEXT NOT NULL, profile TEXT, environment TEXT, base_url TEXT)`,
+	`CREATE TABLE portfolios (portfolio_id TEXT PRIMARY KEY, name TEXT, collateral TEXT, summary TEXT, data TEXT NOT NULL)`,
+	`CREATE TABLE balances (portfolio_id TEXT NOT NULL, asset TEXT NOT NULL, quantity TEXT, hold TEXT, data TEXT NOT NULL)`,
+	`CREATE TABLE positions (portfolio_id TEXT NOT NULL, symbol TEXT NOT NULL, net_size TEXT, mark_price TEXT, data TEXT NOT NULL)`,
+	`CREATE TABLE open_orders (portfolio_id TEXT NOT NULL, order_id TEXT NOT NULL, symbol TEXT, side TEXT, type TEXT, size TEXT, price TEXT, data TEXT NOT NULL)`,
+}
+
+func writeSqliteSnapshot(path string, snapshot *Snapshot) error {
+	db, err := OpenSqlite(path, snapshotSchema...)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return execInTx(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO snapshot VALUES (?, ?, ?, ?, ?)`,
+			snapshot.Version, snapshot.CreatedAt, snapshot.Profile, snapshot.Environment, snapshot.BaseUrl); err != nil {
+			return fmt.Errorf("cannot write snapshot: %w", err)
+		}
+
+		for _, state := range snapshot.Portfolios {
+			portfolioId := state.Portfolio.PortfolioId
+			collateral := ""
+			if state.Summary != nil {
+				collateral = state.Summary.Collateral
+			}
+			if _, err := tx.Exec(`INSERT INTO portfolios VALUES (?, ?, ?, ?, ?)`,
+				portfolioId, state.Portfolio.Name, collateral, mustJson(state.Summary), mustJson(state.Portfolio)); err != nil {
+				return fmt.Errorf("cannot write portfolio %s: %w", portfolioId, err)
+			}
+			for _, b := range state.Balances {
+				if _, err := tx.Exec(`INSERT INTO balances VALUES (?, ?, ?, ?, ?)`,
+					portfolioId, firstNonEmpty(b.AssetName, b.AssetId), b.Quantity, b.Hold, mustJson(b)); err != nil {
+					return fmt.Errorf("cannot write balance: %w", err)
+				}
+			}
+			for _, p := range state.Positions {
+				if _, err := tx.Exec(`INSERT INTO positions VALUES (?, ?, ?, ?, ?)`,
+					portfolioId, p.Symbol, p.NetSize, p.MarkPrice, mustJson(p)); err != nil {
+					return fmt.Errorf("cannot write position: %w", err)
+				}
+			}
+			for _, o := range state.OpenOrders {
+				if _, err := tx.Exec(`INSERT INTO open_orders VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+					portfolioId, o.OrderId, o.Symbol, o.Side, o.Type, o.Size, o.Price, mustJson(o)); err != nil {
+					return fmt.Errorf("cannot write order: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func readSqliteSnapshot(path string) (*Snapshot, error) {
+	db, err := OpenSqlite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	snapshot := &Snapshot{}
+	var profile, environment, baseUrl sql.NullString
+	if err := db.QueryRow(`SELECT version, created_at, profile, environment, base_url FROM snapshot`).
+		Scan(&snapshot.Version, &snapshot.CreatedAt, &profile, &environment, &baseUrl); err != nil {
+		return nil, fmt.Errorf("cannot read snapshot %s: %w", path, err)
+	}
+	snapshot.Profile, snapshot.Environment, snapshot.BaseUrl = profile.String, environment.String, baseUrl.String
+
+	states := map[string]*PortfolioState{}
+	err = scanJsonRows(db, `SELECT portfolio_id, summary, data FROM portfolios ORDER BY rowid`, func(portfolioId string, data ...[]byte) error {
+		state := &PortfolioState{Portfolio: &intx.Portfolio{}}
+		if err := json.Unmarshal(data[0], &state.Summary); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data[1], state.Portfolio); err != nil {
+			return err
+		}
+		states[portfolioId] = state
+		snapshot.Portfolios = append(snapshot.Portfolios, state)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read portfolios from %s: %w", path, err)
+	}
+
+	err = scanJsonRows(db, `SELECT portfolio_id, data FROM balances ORDER BY rowid`, func(portfolioId string, data ...[]byte) error {
+		var b intx.Balance
+		if err := json.Unmarshal(data[0], &b); err != nil {
+			return err
+		}
+		if state, ok := states[portfolioId]; ok {
+			state.Balances = append(state.Balances, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read balances from %s: %w", path, err)
+	}
+
+	err = scanJsonRows(db, `SELECT portfolio_id, data FROM positions ORDER BY rowid`, func(portfolioId string, data ...[]byte) error {
+		var p intx.Position
+		if err := json.Unmarshal(data[0], &p); err != nil {
+			return err
+		}
+		if state, ok := states[portfolioId]; ok {
+			state.Positions = append(state.Positions, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read positions from %s: %w", path, err)
+	}
+
+	err = scanJsonRows(db, `SELECT portfolio_id, data FROM open_orders ORDER BY rowid`, func(portfolioId string, data ...[]byte) error {
+		var o intx.Order
+		if err := json.Unmarshal(data[0], &o); err != nil {
+			return err
+		}
+		if state, ok := states[portfolioId]; ok {
+			state.OpenOrders = append(state.OpenOrders, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read open orders from %s: %w", path, err)
+	}
+
+	return snapshot, nil
+}
+
+// scanJsonRows calls f with the first column of each row as a string and the
+// remaining columns as raw JSON.
+func scanJsonRows(db *sql.DB, query string, f func(key string, data ...[]byte) error) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var key string
+		data := make([][]byte, len(columns)-1)
+		dest := []interface{}{&key}
+		for i := range data {
+			dest = append(dest, &data[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err := f(key, data...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// mustJson encodes values that are known to be encodable, such as SDK
+// structs.
+func mustJson(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,58 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// OpenSqlite opens, creating if needed, a SQLite database and applies the
+// schema statements in order.
+func OpenSqlite(path string, schema ...string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", path, err)
+	}
+
+	// Writes are serialized by SQLite anyway; a single connection avoids
+	// SQLITE_BUSY errors between connections of this process.
+	db.SetMaxOpenConns(1)
+
+	for _, statement := range append([]string{"PRAGMA busy_timeout = 5000"}, schema...) {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("cannot initialize %s: %w", path, err)
+		}
+	}
+	return db, nil
+}
+
+// execInTx runs f in a transaction, committing only if it succeeds.
+func execInTx(db *sql.DB, f func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
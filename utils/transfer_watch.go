@@ -0,0 +1,152 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Backoff yields poll intervals that grow from Initial by Factor up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	Factor  float64
+	next    time.Duration
+}
+
+func (b *Backoff) Next() time.Duration {
+	if b.next == 0 {
+		b.next = b.Initial
+	}
+	current := b.next
+	b.next = time.Duration(float64(b.next) * b.Factor)
+	if b.next > b.Max {
+		b.next = b.Max
+	}
+	return current
+}
+
+func (b *Backoff) Reset() {
+	b.next = 0
+}
+
+// TransferTracker remembers the last seen state of watched transfers.
+type TransferTracker struct {
+	last  map[string]*TransferDetails
+	order []string
+}
+
+func NewTransferTracker() *TransferTracker {
+	return &TransferTracker{last: map[string]*TransferDetails{}}
+}
+
+func (t *TransferTracker) Tracks(transferUuid string) bool {
+	_, ok := t.last[transferUuid]
+	return ok
+}
+
+// Track starts watching a transfer whose state is not known yet.
+func (t *TransferTracker) Track(transferUuid string) {
+	if !t.Tracks(transferUuid) {
+		t.last[transferUuid] = nil
+		t.order = append(t.order, transferUuid)
+	}
+}
+
+// Update records the current state of a transfer and returns the previous
+// state, or nil, when its status, transaction hash or confirmations changed.
+func (t *TransferTracker) Update(current *TransferDetails) (previous *TransferDetails, changed bool) {
+	t.Track(current.TransferUuid)
+	previous = t.last[current.TransferUuid]
+	t.last[current.TransferUuid] = current
+	if previous == nil {
+		return nil, true
+	}
+	changed = previous.Status != current.Status ||
+		previous.TxHash != current.TxHash ||
+		!equalInt64Ptr(previous.Confirmations, current.Confirmations)
+	return previous, changed
+}
+
+// Pending returns the watched transfers that are not known to be final.
+func (t *TransferTracker) Pending() []string {
+	var pending []string
+	for _, uuid := range t.order {
+		if d := t.last[uuid]; d == nil || !IsTransferFinal(d.Status) {
+			pending = append(pending, uuid)
+		}
+	}
+	return pending
+}
+
+// Transfers returns the last state of the watched transfers in the order they
+// were first seen. Transfers not fetched yet are left out.
+func (t *TransferTracker) Transfers() []*TransferDetails {
+	var transfers []*TransferDetails
+	for _, uuid := range t.order {
+		if d := t.last[uuid]; d != nil {
+			transfers = append(transfers, d)
+		}
+	}
+	return transfers
+}
+
+// Outcome returns nil when every watched transfer was processed, and an
+// ExitError for failed transfers, or for pending ones when timedOut.
+func (t *TransferTracker) Outcome(timedOut bool) error {
+	var failed []string
+	for _, d := range t.Transfers() {
+		if strings.EqualFold(d.Status, TransferStatusFailed) {
+			failed = append(failed, d.TransferUuid)
+		}
+	}
+	if len(failed) > 0 {
+		return &ExitError{Code: ExitCodeFailed, Err: fmt.Errorf("transfers failed: %s", strings.Join(failed, ", "))}
+	}
+	if pending := t.Pending(); timedOut && len(pending) > 0 {
+		return &ExitError{Code: ExitCodeTimeout, Err: fmt.Errorf("timed out waiting for transfers: %s", strings.Join(pending, ", "))}
+	}
+	return nil
+}
+
+// FormatTransferTransition describes a change of a transfer for watch output.
+func FormatTransferTransition(previous, current *TransferDetails) string {
+	status := current.Status
+	if previous != nil && previous.Status != current.Status {
+		status = previous.Status + " -> " + current.Status
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s  %s %s %s  %s",
+		time.Now().UTC().Format(time.RFC3339), current.TransferUuid, current.Type, FormatAmount(current.Amount), current.Asset, status)
+	if current.TxHash != "" {
+		fmt.Fprintf(&b, "  tx %s", current.TxHash)
+	}
+	if current.Confirmations != nil {
+		fmt.Fprintf(&b, "  %d confirmations", *current.Confirmations)
+	}
+	return b.String()
+}
+
+func equalInt64Ptr(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,193 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	TransferTypeWithdraw = "WITHDRAW"
+	TransferTypeInternal = "INTERNAL"
+
+	TransferStatusProcessed = "PROCESSED"
+	TransferStatusFailed    = "FAILED"
+)
+
+// IsTransferFinal reports whether a transfer can no longer change status.
+func IsTransferFinal(status string) bool {
+	return strings.EqualFold(status, TransferStatusProcessed) || strings.EqualFold(status, TransferStatusFailed)
+}
+
+// Transfer is a transfer as returned by the API. The SDK decodes addresses
+// as numbers, which fails for any transfer with an address, so transfers are
+// decoded into this type instead.
+type Transfer struct {
+	TransferUuid       string               `json:"transfer_uuid"`
+	Type               string               `json:"type"`
+	Amount             float64              `json:"amount"`
+	Asset              string               `json:"asset"`
+	Status             string               `json:"status"`
+	NetworkName        string               `json:"network_name"`
+	CreatedAt          string               `json:"created_at"`
+	UpdatedAt          string               `json:"updated_at"`
+	FromPortfolio      intx.PortfolioSubset `json:"from_portfolio"`
+	ToPortfolio        intx.PortfolioSubset `json:"to_portfolio"`
+	FromAddress        string               `json:"from_address"`
+	ToAddress          string               `json:"to_address"`
+	FromCbAccount      string               `json:"from_cb_account"`
+	ToCbAccount        string               `json:"to_cb_account"`
+	FromCounterpartyId string               `json:"from_counterparty_id"`
+	ToCounterpartyId   string               `json:"to_counterparty_id"`
+	InstrumentId       int64                `json:"instrument_id"`
+	PositionId         string               `json:"position_id"`
+}
+
+// TransferDetails is a transfer together with on-chain details that the API
+// returns for withdrawals and deposits but the SDK does not decode.
+type TransferDetails struct {
+	Transfer
+	TxHash        string `json:"tx_hash,omitempty"`
+	Confirmations *int64 `json:"confirmations,omitempty"`
+}
+
+var (
+	txHashKeys       = []string{"tx_hash", "txn_hash", "transaction_hash", "blockchain_tx_hash", "onchain_tx_hash"}
+	confirmationKeys = []string{"confirmations", "num_confirmations", "confirmation_count"}
+)
+
+// GetTransfer fetches a single transfer.
+func GetTransfer(client *intx.Client, transferUuid string) (*Transfer, error) {
+	details, err := GetTransferDetails(client, transferUuid)
+	if err != nil {
+		return nil, err
+	}
+	return &details.Transfer, nil
+}
+
+// GetTransferDetails fetches a single transfer with its on-chain details. The
+// SDK decodes this endpoint as a list, so the response is accepted either as
+// an object or as a list.
+func GetTransferDetails(client *intx.Client, transferUuid string) (*TransferDetails, error) {
+	ctx, cancel := GetContextWithTimeout()
+	defer cancel()
+
+	var raw json.RawMessage
+	if err := CallApi(ctx, client, "GET", "/transfers/"+transferUuid, nil, nil, &raw); err != nil {
+		return nil, fmt.Errorf("cannot get transfer %s: %w", transferUuid, err)
+	}
+
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("cannot parse transfer %s: %w", transferUuid, err)
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("transfer %s not found", transferUuid)
+		}
+		raw = list[0]
+	}
+
+	details := &TransferDetails{}
+	if err := json.Unmarshal(raw, &details.Transfer); err != nil {
+		return nil, fmt.Errorf("cannot parse transfer %s: %w", transferUuid, err)
+	}
+
+	if details.TransferUuid == "" {
+		details.TransferUuid = transferUuid
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("cannot parse transfer %s: %w", transferUuid, err)
+	}
+	for _, key := range txHashKeys {
+		if v, ok := fields[key].(string); ok && v != "" {
+			details.TxHash = v
+			break
+		}
+	}
+	for _, key := range confirmationKeys {
+		if v, ok := fields[key]; ok {
+			if n, err := strconv.ParseInt(fmt.Sprint(v), 10, 64); err == nil {
+				details.Confirmations = &n
+				break
+			}
+		}
+	}
+	return details, nil
+}
+
+// FindPortfolioTransfer looks up the internal transfer created by request,
+// since CreatePortfolioTransfer does not return its ID. Transfers in exclude
+// have already been matched to other requests.
+func FindPortfolioTransfer(client *intx.Client, request *intx.CreatePortfolioTransferRequest, since time.Time, exclude map[string]bool) (*Transfer, error) {
+	amount, err := ParseAmount(request.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := ListAllTransfers(client, TransferFilter{
+		PortfolioIds: request.From,
+		Type:         TransferTypeInternal,
+		TimeFrom:     since.Add(-time.Minute).UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range transfers {
+		if exclude[t.TransferUuid] {
+			continue
+		}
+		if strings.EqualFold(t.Asset, request.AssetId) &&
+			math.Abs(t.Amount-amount) < 1e-9 &&
+			isPortfolio(t.ToPortfolio, request.To) {
+			return &transfers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func isPortfolio(p intx.PortfolioSubset, id string) bool {
+	return p.Id == id || p.Uuid == id || p.Name == id
+}
+
+// WaitForTransfer polls a transfer until it is final or timeout elapses, and
+// returns the last status seen. It fails when the base context is done.
+func WaitForTransfer(client *intx.Client, transferUuid string, interval, timeout time.Duration) (*Transfer, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		transfer, err := GetTransfer(client, transferUuid)
+		if err != nil {
+			return nil, err
+		}
+		if IsTransferFinal(transfer.Status) || time.Now().Add(interval).After(deadline) {
+			return transfer, nil
+		}
+		if err := Sleep(BaseContext(), interval); err != nil {
+			return transfer, fmt.Errorf("stopped waiting for transfer %s: %w", transferUuid, err)
+		}
+	}
+}
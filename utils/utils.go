@@ -23,9 +23,11 @@ import (
 	"fmt"
 	"github.com/coinbase-samples/intx-sdk-go"
 	"github.com/spf13/cobra"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -39,23 +41,87 @@ func getDefaultTimeoutDuration() time.Duration {
 	return 7 * time.Second
 }
 
+var (
+	processMu     sync.Mutex
+	baseContext   = context.Background()
+	sharedClients map[string]*intx.Client
+)
+
 func GetContextWithTimeout() (context.Context, context.CancelFunc) {
 	timeoutDuration := getDefaultTimeoutDuration()
-	return context.WithTimeout(context.Background(), timeoutDuration)
+	return context.WithTimeout(BaseContext(), timeoutDuration)
+}
+
+// SetBaseContext makes the contexts of API calls derive from ctx, so that
+// cancelling it aborts the calls of a command run in-process. It returns the
+// previous base context.
+func SetBaseContext(ctx context.Context) context.Context {
+	processMu.Lock()
+	defer processMu.Unlock()
+	previous := baseContext
+	baseContext = ctx
+	return previous
+}
+
+// BaseContext returns the context that the contexts of API calls derive from.
+func BaseContext() context.Context {
+	processMu.Lock()
+	defer processMu.Unlock()
+	return baseContext
 }
 
-func GetClientFromEnv() (*intx.Client, error) {
+// Sleep pauses for d, or until ctx is done, in which case it returns the
+// error of ctx. Polling loops use it with BaseContext so that they stop when
+// a command run in-process is cancelled.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReuseClients makes GetClientFromEnv return the same client for the same
+// environment and credentials, for processes that run many commands.
+func ReuseClients() {
+	processMu.Lock()
+	defer processMu.Unlock()
+	if sharedClients == nil {
+		sharedClients = map[string]*intx.Client{}
+	}
+}
+
+func GetClientFromEnv(cmd *cobra.Command) (*intx.Client, error) {
+	environment, err := ResolveEnvironment(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve environment: %w", err)
+	}
+
+	rawCredentials := os.Getenv(environment.CredentialsEnv)
+	key := environment.BaseUrl + "\x00" + rawCredentials
+	processMu.Lock()
+	defer processMu.Unlock()
+	if client, ok := sharedClients[key]; ok {
+		return client, nil
+	}
+
 	credentials := &intx.Credentials{}
-	if err := json.Unmarshal([]byte(os.Getenv("INTX_CREDENTIALS")), credentials); err != nil {
+	if err := json.Unmarshal([]byte(rawCredentials), credentials); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal credentials: %w", err)
 	}
 
-	client := intx.NewClient(credentials, http.Client{})
+	client := intx.NewClient(credentials, http.Client{}).BaseUrl(environment.BaseUrl)
+	if sharedClients != nil {
+		sharedClients[key] = client
+	}
 	return client, nil
 }
 
 func InitClientAndPortfolioId(cmd *cobra.Command, needPortfolioId bool) (client *intx.Client, portfolioId string, err error) {
-	client, err = GetClientFromEnv()
+	client, err = GetClientFromEnv(cmd)
 	if err != nil {
 		err = fmt.Errorf("cannot get client from environment: %w", err)
 		return
@@ -205,7 +271,72 @@ func RegisterCommandConfigs(root *cobra.Command, cmdConfigs []CommandConfig) {
 					fmt.Printf("could not mark flag %s as required: %v\n", flag.FlagName, err)
 				}
 			}
-			root.AddCommand(config.Command)
 		}
+		root.AddCommand(config.Command)
+	}
+}
+
+// GetFlagTimeValue parses an RFC3339 timestamp or a YYYY-MM-DD date (UTC).
+// A blank flag yields the zero time.
+func GetFlagTimeValue(cmd *cobra.Command, flagName string) (time.Time, error) {
+	value := GetFlagStringValue(cmd, flagName)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return ParseTime(value)
+}
+
+// GetFlagDurationValue parses a duration flag such as 30s or 5m.
+func GetFlagDurationValue(cmd *cobra.Command, flagName string) (time.Duration, error) {
+	value := GetFlagStringValue(cmd, flagName)
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --%s %s: use a duration such as 30s or 5m", flagName, value)
+	}
+	return d, nil
+}
+
+func ParseTime(value string) (time.Time, error) {
+	return ParseTimeIn(value, time.UTC)
+}
+
+// ParseTimeIn is ParseTime with dates taken as midnight in loc.
+func ParseTimeIn(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(DateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s: use RFC3339 or %s", value, DateLayout)
+	}
+	return t, nil
+}
+
+func InTimeRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+	return true
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// OpenOutput returns a writer for the output flag value, defaulting to stdout.
+func OpenOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s: %w", path, err)
 	}
+	return f, nil
 }
@@ -0,0 +1,321 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	withdrawalLimitsFileName = "withdrawal_limits.json"
+
+	withdrawalHistoryRetention = 48 * time.Hour
+)
+
+type WithdrawalRecord struct {
+	Id          string `json:"id,omitempty"`
+	Timestamp   string `json:"timestamp"`
+	AssetId     string `json:"assetId"`
+	Amount      string `json:"amount"`
+	Destination string `json:"destination"`
+}
+
+// WithdrawalLimits holds the locally configured daily limits per asset,
+// network fee estimates per network ARN ID and the recent withdrawals the
+// limits are enforced against. The API does not return network fees, so the
+// estimates are maintained by the user and may differ from the actual fee.
+type WithdrawalLimits struct {
+	DailyLimits         map[string]string   `json:"dailyLimits"`
+	NetworkFeeEstimates map[string]string   `json:"networkFees"`
+	History             []*WithdrawalRecord `json:"history"`
+}
+
+type WithdrawalCheck struct {
+	AssetId          string `json:"assetId"`
+	NetworkArnId     string `json:"networkArnId"`
+	NetworkName      string `json:"networkName,omitempty"`
+	Address          string `json:"address,omitempty"`
+	AddressFormat    string `json:"addressFormat,omitempty"`
+	Amount           string `json:"amount"`
+	EstimatedFee     string `json:"estimatedFee,omitempty"`
+	NetAmount        string `json:"netAmount,omitempty"`
+	TotalDebit       string `json:"totalDebit,omitempty"`
+	MinWithdrawalAmt string `json:"minWithdrawalAmt,omitempty"`
+	MaxWithdrawalAmt string `json:"maxWithdrawalAmt,omitempty"`
+	DailyLimit       string `json:"dailyLimit,omitempty"`
+	WithdrawnToday   string `json:"withdrawnToday,omitempty"`
+}
+
+func GetWithdrawalLimitsPath() (string, error) {
+	return GetCliHomePath(withdrawalLimitsFileName)
+}
+
+func newWithdrawalLimits() *WithdrawalLimits {
+	return &WithdrawalLimits{
+		DailyLimits:         map[string]string{},
+		NetworkFeeEstimates: map[string]string{},
+	}
+}
+
+func LoadWithdrawalLimits() (*WithdrawalLimits, error) {
+	path, err := GetWithdrawalLimitsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := newWithdrawalLimits()
+	if err := ReadJsonFile(path, limits); err != nil {
+		return nil, err
+	}
+	limits.init()
+	return limits, nil
+}
+
+func UpdateWithdrawalLimits(update func(limits *WithdrawalLimits) error) error {
+	path, err := GetWithdrawalLimitsPath()
+	if err != nil {
+		return err
+	}
+
+	limits := newWithdrawalLimits()
+	return UpdateJsonFile(path, limits, func() error {
+		limits.init()
+		return update(limits)
+	})
+}
+
+func (l *WithdrawalLimits) init() {
+	if l.DailyLimits == nil {
+		l.DailyLimits = map[string]string{}
+	}
+	if l.NetworkFeeEstimates == nil {
+		l.NetworkFeeEstimates = map[string]string{}
+	}
+}
+
+// WithdrawnOn sums the recorded withdrawals of assetId on the UTC day of t.
+func (l *WithdrawalLimits) WithdrawnOn(assetId string, t time.Time) float64 {
+	day := t.UTC().Format(DateLayout)
+	total := 0.0
+	for _, r := range l.History {
+		if strings.EqualFold(r.AssetId, assetId) && strings.HasPrefix(r.Timestamp, day) {
+			total += ParseAmountOrZero(r.Amount)
+		}
+	}
+	return total
+}
+
+// CheckDailyLimit fails when debiting amount would exceed the configured
+// daily limit for assetId.
+func (l *WithdrawalLimits) CheckDailyLimit(assetId string, amount float64) (limit, used float64, err error) {
+	limitStr, ok := l.DailyLimits[strings.ToUpper(assetId)]
+	if !ok {
+		return 0, 0, nil
+	}
+
+	if limit, err = ParseAmount(limitStr); err != nil {
+		return 0, 0, fmt.Errorf("invalid daily limit for %s: %w", assetId, err)
+	}
+
+	used = l.WithdrawnOn(assetId, time.Now())
+	if used+amount > limit {
+		return limit, used, fmt.Errorf(
+			"withdrawal of %s %s exceeds the daily limit of %s (already withdrawn today: %s)",
+			FormatAmount(amount), assetId, FormatAmount(limit), FormatAmount(used),
+		)
+	}
+	return limit, used, nil
+}
+
+// NewWithdrawalReservationId returns an ID for ReserveWithdrawal.
+func NewWithdrawalReservationId() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// ReserveWithdrawal checks a debit of amount against the daily limit for
+// assetId and records it under id in the same critical section, so concurrent
+// withdrawals cannot together exceed the limit. An earlier reservation with
+// the same id, left by a retried withdrawal, is replaced. The id is passed to
+// ReleaseWithdrawal when the withdrawal is not sent after all.
+func ReserveWithdrawal(id, assetId string, amount float64, destination string) (limit, used float64, err error) {
+	err = UpdateWithdrawalLimits(func(limits *WithdrawalLimits) error {
+		now := time.Now().UTC()
+		history := limits.History[:0]
+		for _, r := range limits.History {
+			if r.Id == id {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339, r.Timestamp); err == nil && now.Sub(t) < withdrawalHistoryRetention {
+				history = append(history, r)
+			}
+		}
+		limits.History = history
+
+		if limit, used, err = limits.CheckDailyLimit(assetId, amount); err != nil {
+			return err
+		}
+
+		limits.History = append(limits.History, &WithdrawalRecord{
+			Id:          id,
+			Timestamp:   now.Format(time.RFC3339),
+			AssetId:     strings.ToUpper(assetId),
+			Amount:      FormatAmount(amount),
+			Destination: destination,
+		})
+		return nil
+	})
+	return limit, used, err
+}
+
+// ReleaseWithdrawal removes a reservation made by ReserveWithdrawal.
+func ReleaseWithdrawal(id string) error {
+	return UpdateWithdrawalLimits(func(limits *WithdrawalLimits) error {
+		for i, r := range limits.History {
+			if r.Id == id {
+				limits.History = append(limits.History[:i], limits.History[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// Debit returns the amount the withdrawal takes from the balance: the amount
+// plus the estimated network fee when the fee is added to the total.
+func (c *WithdrawalCheck) Debit() float64 {
+	if c.TotalDebit != "" {
+		return ParseAmountOrZero(c.TotalDebit)
+	}
+	return ParseAmountOrZero(c.Amount)
+}
+
+// CheckCryptoWithdrawal validates a withdrawal before it is sent: the asset
+// must be supported on the network, the address must match the network's
+// format and the amount must be within the network limits. The daily limit
+// is checked by ReserveWithdrawal.
+func CheckCryptoWithdrawal(
+	ctx context.Context,
+	client *intx.Client,
+	destination *AddressBookEntry,
+	amountStr string,
+	addNetworkFeeToTotal bool,
+) (*WithdrawalCheck, error) {
+	amount, err := ParseAmount(amountStr)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	check := &WithdrawalCheck{
+		AssetId:      destination.AssetId,
+		NetworkArnId: destination.NetworkArnId,
+		Address:      destination.Address,
+		Amount:       amountStr,
+	}
+
+	response, err := client.GetSupportedNetworks(ctx, &intx.GetSupportedNetworksRequest{AssetId: destination.AssetId})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get supported networks: %w", err)
+	}
+
+	var network *intx.Network
+	for i := range response.NetworkDetail {
+		if response.NetworkDetail[i].NetworkArnId == destination.NetworkArnId {
+			network = &response.NetworkDetail[i]
+			break
+		}
+	}
+	if network == nil {
+		return nil, fmt.Errorf("asset %s is not supported on network %s", destination.AssetId, destination.NetworkArnId)
+	}
+	check.NetworkName = network.NetworkName
+	check.MinWithdrawalAmt = network.MinWithdrawalAmt
+	check.MaxWithdrawalAmt = network.MaxWithdrawalAmt
+
+	check.AddressFormat = AddressFormatForNetwork(network.NetworkName)
+	if check.AddressFormat == "" {
+		check.AddressFormat = AddressFormatForNetwork(network.NetworkArnId)
+	}
+	if check.AddressFormat != "" {
+		if err := ValidateAddress(check.AddressFormat, destination.Address); err != nil {
+			return nil, fmt.Errorf("invalid address for %s: %w", network.NetworkName, err)
+		}
+	}
+
+	if minAmount := ParseAmountOrZero(network.MinWithdrawalAmt); minAmount > 0 && amount < minAmount {
+		return nil, fmt.Errorf("amount %s is below the network minimum of %s", amountStr, network.MinWithdrawalAmt)
+	}
+	if maxAmount := ParseAmountOrZero(network.MaxWithdrawalAmt); maxAmount > 0 && amount > maxAmount {
+		return nil, fmt.Errorf("amount %s is above the network maximum of %s", amountStr, network.MaxWithdrawalAmt)
+	}
+
+	limits, err := LoadWithdrawalLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	if feeStr, ok := limits.NetworkFeeEstimates[destination.NetworkArnId]; ok {
+		fee, err := ParseAmount(feeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network fee estimate for %s: %w", destination.NetworkArnId, err)
+		}
+		check.EstimatedFee = feeStr
+		if addNetworkFeeToTotal {
+			check.NetAmount = FormatAmount(amount)
+			check.TotalDebit = FormatAmount(amount + fee)
+		} else {
+			check.NetAmount = FormatAmount(amount - fee)
+			check.TotalDebit = FormatAmount(amount)
+		}
+		if amount-fee <= 0 && !addNetworkFeeToTotal {
+			return nil, fmt.Errorf("amount %s does not cover the locally configured network fee estimate of %s", amountStr, feeStr)
+		}
+	}
+
+	if _, ok := limits.DailyLimits[strings.ToUpper(destination.AssetId)]; ok && addNetworkFeeToTotal && check.EstimatedFee == "" {
+		return nil, fmt.Errorf("cannot apply the daily limit for %s to the amount plus network fee: no fee estimate for %s, see withdrawal-limits set-network-fee", destination.AssetId, destination.NetworkArnId)
+	}
+
+	return check, nil
+}
+
+// Describe renders the check as a short human readable summary.
+func (c *WithdrawalCheck) Describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Withdrawing %s %s to %s on %s", c.Amount, c.AssetId, c.Address, firstNonEmpty(c.NetworkName, c.NetworkArnId))
+	if c.AddressFormat != "" {
+		fmt.Fprintf(&b, " (%s address format valid)", c.AddressFormat)
+	} else {
+		b.WriteString(" (address format not checked for this network)")
+	}
+	if c.EstimatedFee != "" {
+		fmt.Fprintf(&b, "\nLocal network fee estimate (not from the exchange): %s, net amount: %s, total debit: %s", c.EstimatedFee, c.NetAmount, c.TotalDebit)
+	} else {
+		b.WriteString("\nLocal network fee estimate: not configured, see withdrawal-limits set-network-fee")
+	}
+	if c.DailyLimit != "" {
+		fmt.Fprintf(&b, "\nDaily limit: %s, withdrawn today: %s", c.DailyLimit, c.WithdrawnToday)
+	}
+	return b.String()
+}
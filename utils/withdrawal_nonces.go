@@ -0,0 +1,206 @@
+/**
+ * Copyright 2024-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/intx-sdk-go"
+)
+
+const (
+	withdrawalNoncesFileName = "withdrawal_nonces.json"
+
+	WithdrawalAttemptPending   = "pending"
+	WithdrawalAttemptSubmitted = "submitted"
+
+	// Attempts keyed by an input hash only deduplicate retries within this
+	// window, so the same withdrawal can deliberately be repeated later.
+	inputHashAttemptWindow = 24 * time.Hour
+	referenceAttemptWindow = 30 * 24 * time.Hour
+)
+
+// WithdrawalAttempt is the persisted state of one logical withdrawal. Its
+// nonce is reused for every retry so the exchange can reject duplicates.
+type WithdrawalAttempt struct {
+	Key          string          `json:"key"`
+	Reference    string          `json:"reference,omitempty"`
+	Nonce        string          `json:"nonce"`
+	Status       string          `json:"status"`
+	CreatedAt    string          `json:"createdAt"`
+	UpdatedAt    string          `json:"updatedAt"`
+	AssetId      string          `json:"assetId"`
+	Amount       string          `json:"amount"`
+	NetworkArnId string          `json:"networkArnId"`
+	Address      string          `json:"address"`
+	Tries        int             `json:"tries"`
+	TransferUuid string          `json:"transferUuid,omitempty"`
+	Response     json.RawMessage `json:"response,omitempty"`
+}
+
+type withdrawalNonces struct {
+	Attempts map[string]*WithdrawalAttempt `json:"attempts"`
+}
+
+func getWithdrawalNoncesPath() (string, error) {
+	return GetCliHomePath(withdrawalNoncesFileName)
+}
+
+// WithdrawalKey identifies a logical withdrawal by the user's reference or,
+// when none is given, by a hash of the request inputs.
+func WithdrawalKey(reference string, request *intx.CreateWithdrawalToCryptoAddressRequest) string {
+	if reference != "" {
+		return "ref:" + reference
+	}
+
+	input := strings.Join([]string{
+		request.PortfolioId,
+		strings.ToUpper(request.AssetId),
+		request.NetworkArnId,
+		request.Address,
+		request.Amount,
+		strconv.FormatBool(request.AddNetworkFeeToTotal),
+	}, "|")
+	sum := sha256.Sum256([]byte(input))
+	return "hash:" + hex.EncodeToString(sum[:])
+}
+
+// ReserveWithdrawalAttempt returns the attempt for key, creating it with a new
+// nonce when none exists, the previous one has expired or, for attempts
+// without a reference, replace is set. An explicit nonce must match the nonce
+// of an existing attempt.
+func ReserveWithdrawalAttempt(key, reference, nonce string, replace bool, request *intx.CreateWithdrawalToCryptoAddressRequest) (*WithdrawalAttempt, error) {
+	path, err := getWithdrawalNoncesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var attempt *WithdrawalAttempt
+	store := &withdrawalNonces{}
+	err = UpdateJsonFile(path, store, func() error {
+		if store.Attempts == nil {
+			store.Attempts = map[string]*WithdrawalAttempt{}
+		}
+		pruneWithdrawalAttempts(store.Attempts)
+
+		if existing, ok := store.Attempts[key]; ok && !(replace && reference == "") {
+			if nonce != "" && nonce != existing.Nonce {
+				return fmt.Errorf("withdrawal %s already uses nonce %s", key, existing.Nonce)
+			}
+			if reference != "" && (!strings.EqualFold(existing.AssetId, request.AssetId) || existing.Amount != request.Amount) {
+				return fmt.Errorf("reference %s was already used for %s %s", reference, existing.Amount, existing.AssetId)
+			}
+			attempt = existing
+			return nil
+		}
+
+		if nonce == "" {
+			nonce = strconv.FormatInt(time.Now().UnixNano(), 10)
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		attempt = &WithdrawalAttempt{
+			Key:          key,
+			Reference:    reference,
+			Nonce:        nonce,
+			Status:       WithdrawalAttemptPending,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			AssetId:      request.AssetId,
+			Amount:       request.Amount,
+			NetworkArnId: request.NetworkArnId,
+			Address:      request.Address,
+		}
+		store.Attempts[key] = attempt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// SaveWithdrawalAttempt persists the updated state of attempt.
+func SaveWithdrawalAttempt(attempt *WithdrawalAttempt) error {
+	path, err := getWithdrawalNoncesPath()
+	if err != nil {
+		return err
+	}
+
+	store := &withdrawalNonces{}
+	return UpdateJsonFile(path, store, func() error {
+		if store.Attempts == nil {
+			store.Attempts = map[string]*WithdrawalAttempt{}
+		}
+		attempt.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		store.Attempts[attempt.Key] = attempt
+		return nil
+	})
+}
+
+// FindSubmittedWithdrawal looks for a withdrawal created by an earlier
+// attempt whose outcome is unknown, e.g. because the request timed out. A
+// withdrawal matches on asset, amount and destination address, and on
+// networkName when both it and the transfer name a network.
+func FindSubmittedWithdrawal(client *intx.Client, portfolioId string, attempt *WithdrawalAttempt, networkName string) (*Transfer, error) {
+	createdAt, err := time.Parse(time.RFC3339, attempt.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attempt time: %w", err)
+	}
+	amount, err := ParseAmount(attempt.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := ListAllTransfers(client, TransferFilter{
+		PortfolioIds: portfolioId,
+		Type:         TransferTypeWithdraw,
+		TimeFrom:     createdAt.Add(-time.Minute).Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range transfers {
+		if strings.EqualFold(t.Asset, attempt.AssetId) &&
+			math.Abs(t.Amount-amount) < 1e-9 &&
+			strings.EqualFold(t.ToAddress, attempt.Address) &&
+			(networkName == "" || t.NetworkName == "" || strings.EqualFold(t.NetworkName, networkName)) &&
+			!strings.EqualFold(t.Status, TransferStatusFailed) {
+			return &transfers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func pruneWithdrawalAttempts(attempts map[string]*WithdrawalAttempt) {
+	now := time.Now()
+	for key, a := range attempts {
+		window := inputHashAttemptWindow
+		if a.Reference != "" {
+			window = referenceAttemptWindow
+		}
+		if createdAt, err := time.Parse(time.RFC3339, a.CreatedAt); err != nil || now.Sub(createdAt) > window {
+			delete(attempts, key)
+		}
+	}
+}